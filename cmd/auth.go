@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+func authCmd(deps *Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage authentication tokens",
+	}
+
+	cmd.AddCommand(authRefreshCmd(deps))
+	cmd.AddCommand(authKeysCmd(deps))
+
+	return cmd
+}
+
+func authRefreshCmd(deps *Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh",
+		Short: "Force a refresh of the current token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
+				return err
+			}
+
+			if err := deps.API.RefreshToken(cmd.Context()); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s Token refreshed\n", ui.SuccessPrint("✓"))
+			if expiresAt, ok := deps.API.GetTokenInfo()["expires_at"]; ok {
+				fmt.Fprintf(out, "Expires at: %s\n", ui.FormatTime(fmt.Sprint(expiresAt)))
+			}
+			return nil
+		},
+	}
+}
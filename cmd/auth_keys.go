@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+func authKeysCmd(deps *Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage service-account API keys for CI pipelines",
+		Long: `Manage long-lived service-account API keys.
+
+API keys are opaque tokens meant for CI. Use them through DEPLOYAJA_TOKEN:
+  export DEPLOYAJA_TOKEN=<key>
+  aja deploy`,
+	}
+
+	cmd.AddCommand(authKeysCreateCmd(deps))
+	cmd.AddCommand(authKeysListCmd(deps))
+	cmd.AddCommand(authKeysRevokeCmd(deps))
+
+	return cmd
+}
+
+func authKeysCreateCmd(deps *Deps) *cobra.Command {
+	var scopes []string
+	var expiresIn time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a new API key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
+				return err
+			}
+
+			response, err := deps.API.CreateAPIKey(cmd.Context(), api.CreateAPIKeyRequest{
+				Name:      args[0],
+				Scopes:    scopes,
+				ExpiresIn: int(expiresIn.Seconds()),
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s Created API key %s (%s)\n", ui.SuccessPrint("✓"), response.Name, response.ID)
+			if len(response.Scopes) > 0 {
+				fmt.Fprintf(out, "Scopes: %s\n", strings.Join(response.Scopes, ", "))
+			}
+			if response.ExpiresAt != "" {
+				fmt.Fprintf(out, "Expires at: %s\n", ui.FormatTime(response.ExpiresAt))
+			}
+			fmt.Fprintf(out, "\n%s\n\n", response.Key)
+			fmt.Fprintf(out, "%s Store this key now, it won't be shown again\n", ui.WarningPrint("⚠"))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&scopes, "scope", []string{}, "Scope granted to the key, repeatable (e.g. --scope deploy --scope read)")
+	cmd.Flags().DurationVar(&expiresIn, "expires-in", 0, "Key lifetime, e.g. 2160h for 90 days (default: no expiry)")
+
+	return cmd
+}
+
+func authKeysListCmd(deps *Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List API keys",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
+				return err
+			}
+
+			response, err := deps.API.ListAPIKeys(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if len(response.Keys) == 0 {
+				fmt.Fprintf(out, "%s No API keys found\n", ui.WarningPrint("⚠"))
+				return nil
+			}
+
+			headers := []string{"ID", "NAME", "PREFIX", "SCOPES", "CREATED", "EXPIRES", "LAST USED"}
+			var rows [][]string
+
+			for _, key := range response.Keys {
+				rows = append(rows, []string{
+					key.ID,
+					key.Name,
+					valueOrDash(key.Prefix),
+					valueOrDash(strings.Join(key.Scopes, ",")),
+					ui.FormatTime(key.CreatedAt),
+					valueOrDash(ui.FormatTime(key.ExpiresAt)),
+					valueOrDash(ui.FormatTime(key.LastUsedAt)),
+				})
+			}
+
+			fmt.Fprint(out, ui.FormatTable(headers, rows))
+			return nil
+		},
+	}
+}
+
+func authKeysRevokeCmd(deps *Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke ID",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
+				return err
+			}
+
+			if err := deps.API.RevokeAPIKey(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s Revoked API key %s\n", ui.SuccessPrint("✓"), args[0])
+			return nil
+		},
+	}
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -4,36 +4,66 @@ import (
 	"fmt"
 	"path/filepath"
 
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(configCmd())
-}
-
-func configCmd() *cobra.Command {
-	return &cobra.Command{
+func configCmd(deps *Deps) *cobra.Command {
+	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Manage CLI configuration",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
 			home, _ := os.UserHomeDir()
 			configPath := filepath.Join(home, config.ConfigDir)
 
-			fmt.Printf("Configuration file: %s\n", configPath)
-			fmt.Printf("Token file: %s\n", filepath.Join(home, config.ConfigDir, config.TokenFile))
+			fmt.Fprintf(out, "Configuration file: %s\n", configPath)
+			fmt.Fprintf(out, "Context: %s (%s)\n", deps.context().Name, deps.API.GetBaseURL())
+			fmt.Fprintf(out, "Credential store: %s\n", config.GetCredentialStore())
 
-			if apiClient.Token != "" {
-				fmt.Printf("Authentication: %s\n", ui.SuccessPrint("✓ Authenticated"))
+			if deps.API.HasToken() {
+				fmt.Fprintf(out, "Authentication: %s\n", ui.SuccessPrint("✓ Authenticated"))
 			} else {
-				fmt.Printf("Authentication: %s\n", ui.ErrorPrint("✗ Not authenticated"))
+				fmt.Fprintf(out, "Authentication: %s\n", ui.ErrorPrint("✗ Not authenticated"))
 			}
 
 			return nil
 		},
 	}
+
+	cmd.AddCommand(configRenderCmd(deps))
+	return cmd
+}
+
+func configRenderCmd(deps *Deps) *cobra.Command {
+	var fileFlag string
+	var overrides overrideFlags
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the effective deployment config of an environment",
+		Long: `Print the deployment config with the overlay of the environment selected
+with --env merged over it, and the --values and --set overrides applied, as
+deploy, plan and validate see it.
+
+  aja config render --env staging --set resources.replicas=3`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := config.RenderDeploymentConfig(deps.deploymentConfigFile(fileFlag), overrides.options(deps))
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&fileFlag, "file", "f", "", "Path to the base deployment configuration file")
+	overrides.register(cmd)
+	return cmd
 }
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func contextCmd(deps *Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "context",
+		Aliases: []string{"ctx"},
+		Short:   "Manage named contexts for multiple accounts and API endpoints",
+		Long: `Contexts bundle an API URL, a token store and default deployment settings
+under a name, so you can switch between platforms and accounts.
+
+Examples:
+  aja context add staging --api-url https://staging.deployaja.id
+  aja context use staging
+  aja deploy --context production`,
+	}
+
+	cmd.AddCommand(contextListCmd(deps))
+	cmd.AddCommand(contextUseCmd())
+	cmd.AddCommand(contextAddCmd())
+	cmd.AddCommand(contextDeleteCmd())
+
+	return cmd
+}
+
+func contextListCmd(deps *Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List configured contexts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			cliConfig, err := config.LoadCLIConfig()
+			if err != nil {
+				return err
+			}
+
+			contexts := cliConfig.Contexts
+			if _, ok := cliConfig.GetContext(config.DefaultContext); !ok {
+				contexts = append([]config.Context{{Name: config.DefaultContext}}, contexts...)
+			}
+
+			headers := []string{"CURRENT", "NAME", "API URL", "CREDENTIAL STORE", "DEFAULT FILE", "DEFAULT ENV"}
+			var rows [][]string
+
+			for _, c := range contexts {
+				current := ""
+				if c.Name == deps.context().Name {
+					current = ui.SuccessPrint("*")
+				}
+
+				apiURL := contextServerURL(&c)
+
+				store := c.CredentialStore
+				if store == "" {
+					store = "-"
+				}
+
+				file := c.Defaults.File
+				if file == "" {
+					file = "-"
+				}
+
+				env := c.Defaults.Env
+				if env == "" {
+					env = "-"
+				}
+
+				rows = append(rows, []string{current, c.Name, apiURL, store, file, env})
+			}
+
+			fmt.Fprint(out, ui.FormatTable(headers, rows))
+			return nil
+		},
+	}
+}
+
+func contextUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use NAME",
+		Short: "Switch the current context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			name := args[0]
+
+			cliConfig, err := config.LoadCLIConfig()
+			if err != nil {
+				return err
+			}
+
+			if _, ok := cliConfig.GetContext(name); !ok && name != config.DefaultContext {
+				return fmt.Errorf("context '%s' not found", name)
+			}
+
+			cliConfig.CurrentContext = name
+			if err := config.SaveCLIConfig(cliConfig); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s Switched to context %s\n", ui.SuccessPrint("✓"), name)
+			return nil
+		},
+	}
+}
+
+func contextAddCmd() *cobra.Command {
+	var fields config.Context
+	var use bool
+
+	cmd := &cobra.Command{
+		Use:   "add NAME",
+		Short: "Add or update a context",
+		Long: `Add a context, or update an existing one. When updating, only the settings
+given as flags change, the others are kept.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			name := args[0]
+			if err := config.ValidateContextName(name); err != nil {
+				return err
+			}
+
+			cliConfig, err := config.LoadCLIConfig()
+			if err != nil {
+				return err
+			}
+
+			ctx := config.Context{Name: name}
+			existing, exists := cliConfig.GetContext(name)
+			if exists {
+				ctx = *existing
+			}
+
+			flags := cmd.Flags()
+			if flags.Changed("api-url") {
+				ctx.APIURL = fields.APIURL
+			}
+			if flags.Changed("credential-store") {
+				ctx.CredentialStore = fields.CredentialStore
+			}
+			if flags.Changed("file") {
+				ctx.Defaults.File = fields.Defaults.File
+			}
+			if flags.Changed("env") {
+				ctx.Defaults.Env = fields.Defaults.Env
+			}
+			if flags.Changed("registry") {
+				ctx.Defaults.Registry = fields.Defaults.Registry
+			}
+
+			cliConfig.SetContext(ctx)
+			if use {
+				cliConfig.CurrentContext = ctx.Name
+			}
+
+			if err := config.SaveCLIConfig(cliConfig); err != nil {
+				return err
+			}
+
+			if exists {
+				fmt.Fprintf(out, "%s Updated context %s\n", ui.SuccessPrint("✓"), ctx.Name)
+			} else {
+				fmt.Fprintf(out, "%s Added context %s\n", ui.SuccessPrint("✓"), ctx.Name)
+			}
+			if use {
+				fmt.Fprintf(out, "%s Switched to context %s\n", ui.SuccessPrint("✓"), ctx.Name)
+			}
+			if !contextHasToken(&ctx) {
+				fmt.Fprintf(out, "%s Run 'aja login --context %s' to authenticate\n", ui.InfoPrint("💡"), ctx.Name)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fields.APIURL, "api-url", "", "Platform URL (default: DEPLOYAJA_API_URL or https://deployaja.id)")
+	cmd.Flags().StringVar(&fields.CredentialStore, "credential-store", "", "Token store: file, encrypted or a helper name")
+	cmd.Flags().StringVar(&fields.Defaults.File, "file", "", "Default deployment configuration file")
+	cmd.Flags().StringVar(&fields.Defaults.Env, "env", "", "Default environment overlay, e.g. staging for deployaja.staging.yaml")
+	cmd.Flags().StringVar(&fields.Defaults.Registry, "registry", "", "Default Docker registry for deploy")
+	cmd.Flags().BoolVar(&use, "use", false, "Switch to the context after adding it")
+
+	return cmd
+}
+
+// contextHasToken reports whether a token is stored for a context
+func contextHasToken(ctx *config.Context) bool {
+	store, err := config.NewCredentialStore(contextStoreKind(ctx), ctx.Name, contextServerURL(ctx))
+	if err != nil {
+		return false
+	}
+	token, err := store.Get()
+	return err == nil && token != ""
+}
+
+func contextDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete NAME",
+		Aliases: []string{"rm"},
+		Short:   "Delete a context and its stored token",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			name := args[0]
+
+			cliConfig, err := config.LoadCLIConfig()
+			if err != nil {
+				return err
+			}
+
+			ctx, ok := cliConfig.GetContext(name)
+			if !ok {
+				return fmt.Errorf("context '%s' not found", name)
+			}
+
+			store, err := config.NewCredentialStore(contextStoreKind(ctx), ctx.Name, contextServerURL(ctx))
+			if err != nil {
+				return err
+			}
+
+			cliConfig.DeleteContext(name)
+			if err := config.SaveCLIConfig(cliConfig); err != nil {
+				return err
+			}
+
+			if err := store.Erase(); err != nil {
+				fmt.Fprintf(out, "%s Failed to erase token for %s: %v\n", ui.WarningPrint("⚠"), name, err)
+			}
+
+			fmt.Fprintf(out, "%s Deleted context %s\n", ui.SuccessPrint("✓"), name)
+			return nil
+		},
+	}
+}
+
+// deploymentConfigFile returns the deployment config to load: the flag value,
+// the active context's default, or deployaja.yaml
+func (d *Deps) deploymentConfigFile(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	if file := d.context().Defaults.File; file != "" {
+		return file
+	}
+	return config.DeployFile
+}
+
+// deploymentEnv returns the environment whose overlay is merged over the
+// deployment config: --env, DEPLOYAJA_ENV or the active context's default
+func (d *Deps) deploymentEnv() string {
+	if d.Env != "" {
+		return d.Env
+	}
+	return d.context().Defaults.Env
+}
+
+// loadDeploymentConfig loads the default deployment config with the overlay
+// of deploymentEnv merged over it
+func (d *Deps) loadDeploymentConfig() (*config.DeploymentConfig, error) {
+	return config.LoadDeploymentConfigWithOptions(d.deploymentConfigFile(""), config.LoadOptions{
+		Env:    d.deploymentEnv(),
+		Strict: d.Strict,
+	})
+}
+
+// contextServerURL returns the platform URL of a context, falling back to
+// DEPLOYAJA_API_URL and the default platform
+func contextServerURL(ctx *config.Context) string {
+	if ctx.APIURL != "" {
+		return ctx.APIURL
+	}
+	return api.ServerURL()
+}
+
+// contextStoreKind returns the credential store of a context, falling back to
+// the global credentialStore setting
+func contextStoreKind(ctx *config.Context) string {
+	if ctx.CredentialStore != "" {
+		return ctx.CredentialStore
+	}
+	return viper.GetString("credentialStore")
+}
@@ -4,22 +4,20 @@ import (
 	"fmt"
 	"strings"
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(depsCmd())
-}
-
-func depsCmd() *cobra.Command {
+func depsCmd(deps *Deps) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "deps [instance]",
 		Short: "List available dependencies and versions",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
 			var instance string
 			if len(args) > 0 {
 				instance = args[0]
@@ -28,51 +26,51 @@ func depsCmd() *cobra.Command {
 			depType, _ := cmd.Flags().GetString("type")
 			
 			if instance != "" {
-				response, err := apiClient.GetDependencyInstance()
+				response, err := deps.API.GetDependencyInstance(cmd.Context())
 				if err != nil {
 					return err
 				}
-				fmt.Printf("%s Dependency Instance Details\n\n", ui.InfoPrint("🔍"))
+				fmt.Fprintf(out, "%s Dependency Instance Details\n\n", ui.InfoPrint("🔍"))
 				for _, inst := range *&response.Instances {
-					fmt.Printf("ID:        %v\n", inst.ID)
-					fmt.Printf("User ID:   %v\n", inst.UserID)
-					fmt.Printf("Type:      %v\n", inst.Type)
-					fmt.Printf("Created:   %v\n", inst.CreatedAt)
-					fmt.Printf("Updated:   %v\n", inst.UpdatedAt)
-					fmt.Printf("Config:\n")
+					fmt.Fprintf(out, "ID:        %v\n", inst.ID)
+					fmt.Fprintf(out, "User ID:   %v\n", inst.UserID)
+					fmt.Fprintf(out, "Type:      %v\n", inst.Type)
+					fmt.Fprintf(out, "Created:   %v\n", inst.CreatedAt)
+					fmt.Fprintf(out, "Updated:   %v\n", inst.UpdatedAt)
+					fmt.Fprintf(out, "Config:\n")
 					// Pretty print config (assume it's a map or struct)
 					switch cfg := inst.Config.(type) {
 					case map[string]interface{}:
 						for k, v := range cfg {
-							fmt.Printf("  %s: %v\n", k, v)
+							fmt.Fprintf(out, "  %s: %v\n", k, v)
 						}
 					default:
-						fmt.Printf("  %v\n", inst.Config)
+						fmt.Fprintf(out, "  %v\n", inst.Config)
 					}
-					fmt.Println()
+					fmt.Fprintln(out)
 				}
 				return nil
 			} else {
-				fmt.Printf("%s Available Dependencies\n\n", ui.InfoPrint("🔧"))
+				fmt.Fprintf(out, "%s Available Dependencies\n\n", ui.InfoPrint("🔧"))
 			}
-			response, err := apiClient.GetDependencies(depType)
+			response, err := deps.API.GetDependencies(cmd.Context(), depType)
 			if err != nil {
 				return err
 			}
 
 			for _, dep := range response.Dependencies {
-				fmt.Printf("%s\n", color.New(color.Bold).Sprint(dep.Name))
-				fmt.Printf("  Type: %s\n", dep.Type)
-				fmt.Printf("  Versions: %s (default: %s)\n",
+				fmt.Fprintf(out, "%s\n", color.New(color.Bold).Sprint(dep.Name))
+				fmt.Fprintf(out, "  Type: %s\n", dep.Type)
+				fmt.Fprintf(out, "  Versions: %s (default: %s)\n",
 					strings.Join(dep.Versions, ", "), dep.DefaultVersion)
-				fmt.Printf("  Base Cost: $%.2f/month\n", dep.Pricing.Base)
+				fmt.Fprintf(out, "  Base Cost: $%.2f/month\n", dep.Pricing.Base)
 
 				if dep.Pricing.Storage > 0 {
-					fmt.Printf("  Storage: $%.2f/GB/month\n", dep.Pricing.Storage)
+					fmt.Fprintf(out, "  Storage: $%.2f/GB/month\n", dep.Pricing.Storage)
 				}
 
-				fmt.Printf("  Specs: %s, %s\n", dep.Specs.CPU, dep.Specs.Memory)
-				fmt.Println()
+				fmt.Fprintf(out, "  Specs: %s, %s\n", dep.Specs.CPU, dep.Specs.Memory)
+				fmt.Fprintln(out)
 			}
 
 			return nil
@@ -2,51 +2,50 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(describeCmd())
-}
-
-func describeCmd() *cobra.Command {
+func describeCmd(deps *Deps) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "describe DEPLOYMENT_NAME",
 		Short: "Describe deployment pod details (status, containers, events, etc.)",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
 			deploymentName := args[0]
 			if deploymentName == "" {
 				return fmt.Errorf("deployment name is required")
 			}
-			if err := ensureAuthenticated(); err != nil {
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
-			fmt.Printf("%s Fetching pod details for %s...\n", ui.InfoPrint("🔍"), deploymentName)
+			fmt.Fprintf(out, "%s Fetching pod details for %s...\n", ui.InfoPrint("🔍"), deploymentName)
 
-			describeResp, err := apiClient.Describe(deploymentName)
+			describeResp, err := deps.API.Describe(cmd.Context(), deploymentName)
 			if err != nil {
 				return err
 			}
 
 			// Print pod description
-			fmt.Printf("%s Pod Description\n\n", ui.InfoPrint("📦"))
-			printPodDescription(describeResp.Pod)
+			fmt.Fprintf(out, "%s Pod Description\n\n", ui.InfoPrint("📦"))
+			printPodDescription(out, describeResp.Pod)
 
 			// Print events if any
 			if len(describeResp.Events) > 0 {
-				fmt.Printf("\n%s Pod Events\n\n", ui.InfoPrint("📅"))
+				fmt.Fprintf(out, "\n%s Pod Events\n\n", ui.InfoPrint("📅"))
 				for _, event := range describeResp.Events {
-					fmt.Printf("- [%s] %s: %s (count: %v, first: %v, last: %v)\n",
+					fmt.Fprintf(out, "- [%s] %s: %s (count: %v, first: %v, last: %v)\n",
 						event["type"], event["reason"], event["message"],
 						event["count"], event["firstTimestamp"], event["lastTimestamp"])
 				}
 			} else {
-				fmt.Printf("%s No events found for this pod\n", ui.WarningPrint("⚠"))
+				fmt.Fprintf(out, "%s No events found for this pod\n", ui.WarningPrint("⚠"))
 			}
 
 			return nil
@@ -55,21 +54,21 @@ func describeCmd() *cobra.Command {
 	return cmd
 }
 
-func printPodDescription(pod map[string]interface{}) {
-	fmt.Printf("Name:        %v\n", pod["name"])
-	fmt.Printf("Namespace:   %v\n", pod["namespace"])
-	fmt.Printf("Node:        %v\n", pod["nodeName"])
-	fmt.Printf("Phase:       %v\n", pod["phase"])
-	fmt.Printf("Pod IP:      %v\n", pod["podIP"])
-	fmt.Printf("Host IP:     %v\n", pod["hostIP"])
-	fmt.Printf("Start Time:  %v\n", pod["startTime"])
+func printPodDescription(out io.Writer, pod map[string]interface{}) {
+	fmt.Fprintf(out, "Name:        %v\n", pod["name"])
+	fmt.Fprintf(out, "Namespace:   %v\n", pod["namespace"])
+	fmt.Fprintf(out, "Node:        %v\n", pod["nodeName"])
+	fmt.Fprintf(out, "Phase:       %v\n", pod["phase"])
+	fmt.Fprintf(out, "Pod IP:      %v\n", pod["podIP"])
+	fmt.Fprintf(out, "Host IP:     %v\n", pod["hostIP"])
+	fmt.Fprintf(out, "Start Time:  %v\n", pod["startTime"])
 
 	// Conditions
 	if conds, ok := pod["conditions"].([]interface{}); ok && len(conds) > 0 {
-		fmt.Println("Conditions:")
+		fmt.Fprintln(out, "Conditions:")
 		for _, c := range conds {
 			if cond, ok := c.(map[string]interface{}); ok {
-				fmt.Printf("  - Type: %v, Status: %v, Reason: %v, Message: %v\n",
+				fmt.Fprintf(out, "  - Type: %v, Status: %v, Reason: %v, Message: %v\n",
 					cond["type"], cond["status"], cond["reason"], cond["message"])
 			}
 		}
@@ -77,27 +76,27 @@ func printPodDescription(pod map[string]interface{}) {
 
 	// Containers
 	if containers, ok := pod["containers"].([]interface{}); ok && len(containers) > 0 {
-		fmt.Println("Containers:")
+		fmt.Fprintln(out, "Containers:")
 		for _, cs := range containers {
 			if c, ok := cs.(map[string]interface{}); ok {
-				fmt.Printf("  - Name: %v\n", c["name"])
-				fmt.Printf("    Image: %v\n", c["image"])
-				fmt.Printf("    Ready: %v\n", c["ready"])
-				fmt.Printf("    Restarts: %v\n", c["restartCount"])
+				fmt.Fprintf(out, "  - Name: %v\n", c["name"])
+				fmt.Fprintf(out, "    Image: %v\n", c["image"])
+				fmt.Fprintf(out, "    Ready: %v\n", c["ready"])
+				fmt.Fprintf(out, "    Restarts: %v\n", c["restartCount"])
 				if state, ok := c["state"].(map[string]interface{}); ok {
-					fmt.Printf("    State: %v\n", state)
+					fmt.Fprintf(out, "    State: %v\n", state)
 				}
 
 				// Print ports if available
 				if ports, ok := c["ports"].([]interface{}); ok && len(ports) > 0 {
-					fmt.Println("    Ports:")
+					fmt.Fprintln(out, "    Ports:")
 					for _, p := range ports {
 						if port, ok := p.(map[string]interface{}); ok {
 							containerPort := getValueOrNil(port["containerPort"])
 							protocol := getValueOrDefault(port["protocol"], "TCP")
 							name := getValueOrNil(port["name"])
 							hostPort := getValueOrNil(port["hostPort"])
-							fmt.Printf("      - Container Port: %v, Protocol: %v, Name: %v, Host Port: %v\n",
+							fmt.Fprintf(out, "      - Container Port: %v, Protocol: %v, Name: %v, Host Port: %v\n",
 								containerPort, protocol, name, hostPort)
 						}
 					}
@@ -105,26 +104,26 @@ func printPodDescription(pod map[string]interface{}) {
 
 				// Print environment variables if available
 				if env, ok := c["environment"].([]interface{}); ok && len(env) > 0 {
-					fmt.Println("    Environment Variables:")
+					fmt.Fprintln(out, "    Environment Variables:")
 					for _, e := range env {
 						if envVar, ok := e.(map[string]interface{}); ok {
 							name := getValueOrNil(envVar["name"])
 							value := getValueOrNil(envVar["value"])
-							fmt.Printf("      - %v = %v\n", name, value)
+							fmt.Fprintf(out, "      - %v = %v\n", name, value)
 
 							// Print valueFrom if available
 							if valueFrom, ok := envVar["valueFrom"].(map[string]interface{}); ok {
 								if configMapRef := getValueOrNil(valueFrom["configMapKeyRef"]); configMapRef != "<nil>" {
-									fmt.Printf("        (from ConfigMap: %v)\n", configMapRef)
+									fmt.Fprintf(out, "        (from ConfigMap: %v)\n", configMapRef)
 								}
 								if secretRef := getValueOrNil(valueFrom["secretKeyRef"]); secretRef != "<nil>" {
-									fmt.Printf("        (from Secret: %v)\n", secretRef)
+									fmt.Fprintf(out, "        (from Secret: %v)\n", secretRef)
 								}
 								if fieldRef := getValueOrNil(valueFrom["fieldRef"]); fieldRef != "<nil>" {
-									fmt.Printf("        (from Field: %v)\n", fieldRef)
+									fmt.Fprintf(out, "        (from Field: %v)\n", fieldRef)
 								}
 								if resourceFieldRef := getValueOrNil(valueFrom["resourceFieldRef"]); resourceFieldRef != "<nil>" {
-									fmt.Printf("        (from Resource Field: %v)\n", resourceFieldRef)
+									fmt.Fprintf(out, "        (from Resource Field: %v)\n", resourceFieldRef)
 								}
 							}
 						}
@@ -133,13 +132,13 @@ func printPodDescription(pod map[string]interface{}) {
 
 				// Print volume mounts if available
 				if mounts, ok := c["volumeMounts"].([]interface{}); ok && len(mounts) > 0 {
-					fmt.Println("    Volume Mounts:")
+					fmt.Fprintln(out, "    Volume Mounts:")
 					for _, m := range mounts {
 						if mount, ok := m.(map[string]interface{}); ok {
 							name := getValueOrNil(mount["name"])
 							mountPath := getValueOrNil(mount["mountPath"])
 							readOnly := getValueOrDefault(mount["readOnly"], false)
-							fmt.Printf("      - Name: %v, Mount Path: %v, Read Only: %v\n",
+							fmt.Fprintf(out, "      - Name: %v, Mount Path: %v, Read Only: %v\n",
 								name, mountPath, readOnly)
 						}
 					}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/mock"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// MockScheme selects the embedded mock platform as API URL, e.g. mock:// or mock://workshop
+const MockScheme = "mock"
+
+// mockEnvironment is the Mockoon environment answering the routes the mock
+// platform doesn't simulate
+var mockEnvironment []byte
+
+// SetMockEnvironment provides the Mockoon environment embedded in the binary
+func SetMockEnvironment(data []byte) {
+	mockEnvironment = data
+}
+
+func devServerCmd() *cobra.Command {
+	var listen string
+	var statePath string
+	var mockoonFile string
+	var rolloutDelay time.Duration
+	var logInterval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "dev-server",
+		Short: "Run a local mock of the DeployAja platform",
+		Long: `Run an in-process fake of the DeployAja platform for workshops, demos and
+integration tests, without network access or an account.
+
+The mock keeps state across calls: deployments roll out after --rollout-delay,
+show up in 'aja status', stream logs with 'aja logs -f' and disappear with
+'aja drop'. Images tagged ':fail' simulate a crash-looping release. Logins
+are approved immediately. Routes it doesn't simulate are answered from the
+embedded mockoon.json, or from --mockoon.
+
+Instead of running a server, set DEPLOYAJA_API_URL=mock:// to start the mock
+inside every aja command. Its state and token are kept in
+~/.deployaja/mock/default; use mock://NAME for another directory under
+~/.deployaja/mock, or mock:///path for any directory.
+
+Examples:
+  aja dev-server
+  DEPLOYAJA_API_URL=http://localhost:3001 aja deploy
+
+  export DEPLOYAJA_API_URL=mock://
+  aja login && aja deploy && aja status && aja drop my-app`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			routes, err := mockRoutes(mockoonFile)
+			if err != nil {
+				return err
+			}
+
+			server, err := mock.NewServer(mock.Options{
+				Routes:       routes,
+				StatePath:    statePath,
+				RolloutDelay: rolloutDelay,
+				LogInterval:  logInterval,
+			})
+			if err != nil {
+				return err
+			}
+
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %v", listen, err)
+			}
+
+			httpServer := &http.Server{Handler: server}
+			go func() {
+				<-cmd.Context().Done()
+				httpServer.Close()
+			}()
+
+			serverURL := "http://" + listener.Addr().String()
+			fmt.Fprintf(out, "%s Mock platform listening on %s\n", ui.SuccessPrint("🚀"), serverURL)
+			if statePath != "" {
+				fmt.Fprintf(out, "%s State is kept in %s\n", ui.InfoPrint("💾"), statePath)
+			}
+			fmt.Fprintf(out, "%s Point the CLI at it with: export DEPLOYAJA_API_URL=%s\n", ui.InfoPrint("💡"), serverURL)
+			fmt.Fprintln(out, "Press Ctrl+C to stop")
+
+			if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "localhost:3001", "Address to listen on")
+	cmd.Flags().StringVar(&statePath, "state", "", "File to keep the state in across restarts (default: memory only)")
+	cmd.Flags().StringVar(&mockoonFile, "mockoon", "", "Mockoon environment answering the routes the mock doesn't simulate (default: embedded mockoon.json)")
+	cmd.Flags().DurationVar(&rolloutDelay, "rollout-delay", mock.DefaultRolloutDelay, "Time a rollout stays in the deploying state")
+	cmd.Flags().DurationVar(&logInterval, "log-interval", mock.DefaultLogInterval, "Time between generated log lines on 'aja logs -f'")
+
+	return cmd
+}
+
+// mockRoutes loads the Mockoon environment from path, or the embedded one
+func mockRoutes(path string) ([]mock.Route, error) {
+	data := mockEnvironment
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Mockoon environment: %v", err)
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return mock.LoadMockoon(data)
+}
+
+// isMockURL reports whether an API URL selects the embedded mock platform
+func isMockURL(serverURL string) bool {
+	return strings.HasPrefix(serverURL, MockScheme+"://")
+}
+
+// startMockPlatform serves the mock platform selected by a mock:// URL on a
+// loopback port for the lifetime of the process. It returns the URL to call
+// and a credential store kept next to the mock state, so mock logins never
+// touch real tokens. The rollout-delay query parameter overrides the default.
+func startMockPlatform(serverURL string) (string, config.CredentialStore, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid mock URL %s: %v", serverURL, err)
+	}
+
+	dir := u.Path
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil, err
+		}
+		name := u.Host
+		if name == "" {
+			name = "default"
+		}
+		dir = filepath.Join(home, config.ConfigDir, "mock", name)
+	}
+
+	rolloutDelay := mock.DefaultRolloutDelay
+	if value := u.Query().Get("rollout-delay"); value != "" {
+		if rolloutDelay, err = time.ParseDuration(value); err != nil {
+			return "", nil, fmt.Errorf("invalid rollout-delay in %s: %v", serverURL, err)
+		}
+	}
+
+	routes, err := mockRoutes("")
+	if err != nil {
+		return "", nil, err
+	}
+
+	server, err := mock.NewServer(mock.Options{
+		Routes:       routes,
+		StatePath:    filepath.Join(dir, "state.json"),
+		RolloutDelay: rolloutDelay,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start mock platform: %v", err)
+	}
+	go http.Serve(listener, server)
+
+	store := config.NewFileStore(filepath.Join(dir, config.TokenFile))
+	return "http://" + listener.Addr().String(), store, nil
+}
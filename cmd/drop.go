@@ -3,25 +3,22 @@ package cmd
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"strings"
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(dropCmd())
-}
-
-func dropCmd() *cobra.Command {
+func dropCmd(deps *Deps) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "drop NAME",
 		Short: "Delete/destroy deployment",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
@@ -29,25 +26,25 @@ func dropCmd() *cobra.Command {
 			force, _ := cmd.Flags().GetBool("force")
 
 			if !force {
-				fmt.Printf("%s Are you sure you want to delete %s? (y/N): ", ui.WarningPrint("⚠"), name)
-				reader := bufio.NewReader(os.Stdin)
+				fmt.Fprintf(out, "%s Are you sure you want to delete %s? (y/N): ", ui.WarningPrint("⚠"), name)
+				reader := bufio.NewReader(cmd.InOrStdin())
 				response, _ := reader.ReadString('\n')
 				response = strings.TrimSpace(strings.ToLower(response))
 
 				if response != "y" && response != "yes" {
-					fmt.Printf("Cancelled\n")
+					fmt.Fprintf(out, "Cancelled\n")
 					return nil
 				}
 			}
 
-			fmt.Printf("%s Deleting %s...\n", ui.InfoPrint("🗑"), name)
+			fmt.Fprintf(out, "%s Deleting %s...\n", ui.InfoPrint("🗑"), name)
 
-			err := apiClient.Drop(name)
+			err := deps.API.Drop(cmd.Context(), name)
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("%s Deletion initiated for %s\n", ui.SuccessPrint("✓"), name)
+			fmt.Fprintf(out, "%s Deletion initiated for %s\n", ui.SuccessPrint("✓"), name)
 
 			return nil
 		},
@@ -1,35 +1,35 @@
 package cmd
 
 import (
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/ui"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(envCmd())
-}
-
-func envCmd() *cobra.Command {
+func envCmd(deps *Deps) *cobra.Command {
 	var deploymentName string
 
 	cmd := &cobra.Command{
 		Use:   "env [edit|set|get]",
 		Short: "Manage environment variables",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
 
 			if deploymentName == "" {
-				if cfg, err := config.LoadDeploymentConfig(); err == nil && cfg.Name != "" {
+				if cfg, err := deps.loadDeploymentConfig(); err == nil && cfg.Name != "" {
 					deploymentName = cfg.Name
 				}
 			}
-			if err := ensureAuthenticated(); err != nil {
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
@@ -40,18 +40,18 @@ func envCmd() *cobra.Command {
 
 			switch action {
 			case "edit":
-				return editEnvVars(deploymentName)
+				return editEnvVars(cmd.Context(), deps.API, deploymentName)
 			case "get":
 				key := ""
 				if len(args) > 1 {
 					key = args[1]
 				}
-				return getEnvVars(key, deploymentName)
+				return getEnvVars(cmd.Context(), out, deps.API, key, deploymentName)
 			case "set":
 				if len(args) < 2 {
 					return fmt.Errorf("usage: deployaja env set KEY=VALUE")
 				}
-				return setEnvVar(args[1], deploymentName)
+				return setEnvVar(cmd.Context(), out, deps.API, args[1], deploymentName)
 			default:
 				return fmt.Errorf("unknown action: %s", action)
 			}
@@ -61,9 +61,9 @@ func envCmd() *cobra.Command {
 	return cmd
 }
 
-func editEnvVars(deploymentName string) error {
+func editEnvVars(ctx context.Context, client api.DeployAjaAPI, deploymentName string) error {
 	// Get current env vars
-	envVars, err := apiClient.GetEnvVars(deploymentName)
+	envVars, err := client.GetEnvVars(ctx, deploymentName)
 	if err != nil {
 		return err
 	}
@@ -111,31 +111,31 @@ func editEnvVars(deploymentName string) error {
 	}
 
 	// Update env vars
-	return apiClient.UpdateEnvVars(modifiedEnvVars, deploymentName)
+	return client.UpdateEnvVars(ctx, modifiedEnvVars, deploymentName)
 }
 
-func getEnvVars(key string, deploymentName string) error {
-	envVars, err := apiClient.GetEnvVars(deploymentName)
+func getEnvVars(ctx context.Context, out io.Writer, client api.DeployAjaAPI, key string, deploymentName string) error {
+	envVars, err := client.GetEnvVars(ctx, deploymentName)
 	if err != nil {
 		return err
 	}
 
 	if key != "" {
 		if value, exists := envVars[key]; exists {
-			fmt.Printf("%s\n", value)
+			fmt.Fprintf(out, "%s\n", value)
 		} else {
 			return fmt.Errorf("environment variable %s not found", key)
 		}
 	} else {
 		for k, v := range envVars {
-			fmt.Printf("%s=%s\n", k, v)
+			fmt.Fprintf(out, "%s=%s\n", k, v)
 		}
 	}
 
 	return nil
 }
 
-func setEnvVar(keyValue string, deploymentName string) error {
+func setEnvVar(ctx context.Context, out io.Writer, client api.DeployAjaAPI, keyValue string, deploymentName string) error {
 	parts := strings.SplitN(keyValue, "=", 2)
 	if len(parts) != 2 {
 		return fmt.Errorf("invalid format. Use KEY=VALUE")
@@ -145,11 +145,11 @@ func setEnvVar(keyValue string, deploymentName string) error {
 		parts[0]: parts[1],
 	}
 
-	err := apiClient.UpdateEnvVars(envVars, deploymentName)
+	err := client.UpdateEnvVars(ctx, envVars, deploymentName)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("%s Set %s\n", ui.SuccessPrint("✓"), keyValue)
+	fmt.Fprintf(out, "%s Set %s\n", ui.SuccessPrint("✓"), keyValue)
 	return nil
 }
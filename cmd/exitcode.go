@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// Exit codes returned by the CLI so scripts can branch on the failure class
+const (
+	ExitOK           = 0
+	ExitError        = 1
+	ExitUnauthorized = 3
+	ExitNotFound     = 4
+	ExitConflict     = 5
+	ExitRateLimited  = 6
+	ExitServerError  = 7
+	ExitBadRequest   = 8
+
+	// Outcomes of commands that wait for a rollout
+	ExitRolloutFailed  = 9
+	ExitRolloutTimeout = 10
+	ExitRolloutUnknown = 11
+)
+
+// exitCode maps an error returned by a command to the process exit code
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	// Rollout outcomes win over the API failure that made them unknown
+	case errors.Is(err, api.ErrRolloutFailed):
+		return ExitRolloutFailed
+	case errors.Is(err, api.ErrRolloutTimeout):
+		return ExitRolloutTimeout
+	case errors.Is(err, api.ErrRolloutUnknown):
+		return ExitRolloutUnknown
+	case errors.Is(err, api.ErrUnauthorized), errors.Is(err, api.ErrForbidden):
+		return ExitUnauthorized
+	case errors.Is(err, api.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, api.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, api.ErrRateLimited):
+		return ExitRateLimited
+	case errors.Is(err, api.ErrServer):
+		return ExitServerError
+	case errors.Is(err, api.ErrBadRequest), errors.As(err, new(*config.ValidationError)):
+		return ExitBadRequest
+	default:
+		return ExitError
+	}
+}
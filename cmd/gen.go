@@ -5,32 +5,30 @@ import (
 	"os"
 	"os/exec"	
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(genCmd())
-}
-
-func genCmd() *cobra.Command {
+func genCmd(deps *Deps) *cobra.Command {
 	return &cobra.Command{
 		Use:   "gen [prompt]",
 		Short: "Generate aja configuration based on a prompt",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
 			prompt := args[0]
 
-			fmt.Printf("%s Generating content...\n", ui.InfoPrint("🤖"))
+			fmt.Fprintf(out, "%s Generating content...\n", ui.InfoPrint("🤖"))
 
-			response, err := apiClient.Gen(prompt)
+			response, err := deps.API.Gen(cmd.Context(), prompt)
 			if err != nil {
-				return fmt.Errorf("failed to generate content: %v", err)
+				return fmt.Errorf("failed to generate content: %w", err)
 			}
 
 			// Create temporary YAML file with random 2-digit number in filename
@@ -41,8 +39,8 @@ func genCmd() *cobra.Command {
 				return fmt.Errorf("failed to write file: %v", err)
 			}
 
-			fmt.Printf("%s Content written to %s\n", ui.SuccessPrint("✓"), tempFile)
-			fmt.Printf("%s Opening vim...\n", ui.InfoPrint("📝"))
+			fmt.Fprintf(out, "%s Content written to %s\n", ui.SuccessPrint("✓"), tempFile)
+			fmt.Fprintf(out, "%s Opening vim...\n", ui.InfoPrint("📝"))
 
 			// Open vim with the temporary file
 			vimCmd := exec.Command("vim", tempFile)
@@ -6,22 +6,20 @@ import (
 	"os"
 	"time"
 
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
-func init() {
-	rootCmd.AddCommand(initCmd())
-}
-
 func initCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "init",
 		Short: "Create deployaja.yaml configuration file",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
 			if _, err := os.Stat(config.DeployFile); err == nil {
 				return fmt.Errorf("deployaja.yaml already exists")
 			}
@@ -88,15 +86,17 @@ func initCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			// Editors with the YAML language server validate against the schema
+			data = append([]byte(config.SchemaModeline+"\n"), data...)
 
 			err = os.WriteFile(config.DeployFile, data, 0644)
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("%s Created %s with comprehensive configuration\n", ui.SuccessPrint("✓"), config.DeployFile)
-			fmt.Printf("%s Edit the file to customize your application configuration\n", ui.InfoPrint("→"))
-			fmt.Printf("%s Remove unused sections (dependencies, volumes, domain) if not needed\n", ui.InfoPrint("💡"))
+			fmt.Fprintf(out, "%s Created %s with comprehensive configuration\n", ui.SuccessPrint("✓"), config.DeployFile)
+			fmt.Fprintf(out, "%s Edit the file to customize your application configuration\n", ui.InfoPrint("→"))
+			fmt.Fprintf(out, "%s Remove unused sections (dependencies, volumes, domain) if not needed\n", ui.InfoPrint("💡"))
 
 			return nil
 		},
@@ -5,20 +5,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(installCmd())
-}
-
-func installCmd() *cobra.Command {
+func installCmd(deps *Deps) *cobra.Command {
 	var domain string
 	var dryRun bool
 	var name string
+	var wait waitFlags
 
 	cmd := &cobra.Command{
 		Use:   "install [APPNAME]",
@@ -27,94 +27,106 @@ func installCmd() *cobra.Command {
 The configuration will be saved as APPNAME-install.json in the current directory.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
 			appName := args[0]
 
-			fmt.Printf("%s Installing %s from marketplace...\n", ui.InfoPrint("📦"), appName)
+			fmt.Fprintf(out, "%s Installing %s from marketplace...\n", ui.InfoPrint("📦"), appName)
 			if domain != "" {
-				fmt.Printf("%s Using custom domain: %s\n", ui.InfoPrint("🌐"), domain)
+				fmt.Fprintf(out, "%s Using custom domain: %s\n", ui.InfoPrint("🌐"), domain)
 			}
 			if dryRun {
-				fmt.Printf("%s Dry run mode enabled\n", ui.InfoPrint("🔍"))
+				fmt.Fprintf(out, "%s Dry run mode enabled\n", ui.InfoPrint("🔍"))
 			}
 
-			// Get app configuration from API
-			response, err := apiClient.InstallApp(appName, domain, name, dryRun)
+			// The platform installs the configuration as it is, so its
+			// variables are resolved and checked here before anything starts:
+			// preview the installation to get the configuration first
+			preview, err := deps.API.InstallApp(cmd.Context(), appName, domain, name, true)
 			if err != nil {
-				return fmt.Errorf("failed to install app: %v", err)
+				return fmt.Errorf("failed to install app: %w", err)
 			}
-			// Check for successful installation (accept "success", "initiated", "pending" as successful)
-			successStatuses := []string{"success", "initiated", "pending", "deploying", "running", "validated"}
-			isSuccess := false
-			for _, status := range successStatuses {
-				if response.Status == status {
-					isSuccess = true
-					break
-				}
-			}
-
-			if !isSuccess {
-				return fmt.Errorf("installation failed: %s (status: %s)", response.Message, response.Status)
+			if err := checkInstallStatus(preview); err != nil {
+				return err
 			}
 
 			// Decode the base64 config
-			configData, err := base64.StdEncoding.DecodeString(response.Config)
+			configData, err := base64.StdEncoding.DecodeString(preview.Config)
 			if err != nil {
 				return fmt.Errorf("failed to decode configuration: %v", err)
 			}
 
-			// Save config to YAML file
-			filename := fmt.Sprintf("%s.yaml", response.DeploymentName)
+			// Save config to YAML file, with its ${VAR} references kept
+			filename := fmt.Sprintf("%s.yaml", preview.DeploymentName)
 			err = os.WriteFile(filename, configData, 0644)
 			if err != nil {
 				return fmt.Errorf("failed to write config file: %v", err)
 			}
 
 			absPath, _ := filepath.Abs(filename)
-			fmt.Printf("%s Configuration saved to: %s\n", ui.SuccessPrint("✅"), absPath)
-			fmt.Printf("%s %s\n", ui.InfoPrint("💡"), response.Message)			
-			
-
-			// Poll for deployment status until completion
-			fmt.Printf("%s Waiting for deployment to complete...\n", ui.InfoPrint("🔍"))
-
-			var lastStatus string
-			statusCallback := func(status string) {
-				if status != lastStatus {
-					fmt.Printf("%s Status: %s\n", ui.InfoPrint("📊"), status)
-					lastStatus = status
-				}
-			}
+			fmt.Fprintf(out, "%s Configuration saved to: %s\n", ui.SuccessPrint("✅"), absPath)
 
-			finalDeployment, err := apiClient.PollDeploymentStatus(response.DeploymentName, statusCallback)
+			cfg, resolved, variables, err := config.ResolveVariables(filename, configData, deps.Strict)
 			if err != nil {
-				fmt.Printf("%s Warning: Failed to monitor deployment status: %v\n", ui.WarningPrint("⚠️"), err)
-				fmt.Printf("%s You can check the status manually using: deployaja status\n", ui.InfoPrint("💡"))
+				return fmt.Errorf("invalid configuration: %w", err)
+			}
+			if err := reportDiagnostics(out, variables); err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Fprintf(out, "%s %s\n", ui.InfoPrint("💡"), preview.Message)
 				return nil
 			}
 
-			// Show final status
-			if finalDeployment.Status == "running" || finalDeployment.Status == "success" {
-				fmt.Printf("%s Installation completed successfully!\n", ui.SuccessPrint("🎉"))
-				if finalDeployment.URL != "" {
-					fmt.Printf("%s Access your application at: %s\n", ui.InfoPrint("🌐"), finalDeployment.URL)
+			if err := wait.recordPrevious(cmd, deps, preview.DeploymentName); err != nil {
+				return err
+			}
+
+			if resolved {
+				// Installing would deploy the unresolved references, deploy
+				// the resolved configuration instead
+				response, err := deps.API.Deploy(cmd.Context(), cfg, false, "", "", "")
+				if err != nil {
+					return fmt.Errorf("failed to install app: %w", err)
 				}
+				fmt.Fprintf(out, "%s %s\n", ui.InfoPrint("💡"), response.Message)
 			} else {
-				fmt.Printf("%s Installation failed with status: %s\n", ui.ErrorPrint("❌"), finalDeployment.Status)
-				fmt.Printf("%s Use 'deployaja describe %s' for more details\n", ui.InfoPrint("💡"), response.DeploymentName)
-				return fmt.Errorf("installation failed")
+				response, err := deps.API.InstallApp(cmd.Context(), appName, domain, name, false)
+				if err != nil {
+					return fmt.Errorf("failed to install app: %w", err)
+				}
+				if err := checkInstallStatus(response); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s %s\n", ui.InfoPrint("💡"), response.Message)
 			}
 
-			return nil
+			if !wait.enabled() {
+				return nil
+			}
+			return waitForRollout(cmd, deps, preview.DeploymentName, "Installation", &wait)
 		},
 	}
 
 	cmd.Flags().StringVarP(&domain, "domain", "d", "", "Custom domain for the ingress URL")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Perform a dry run without actually installing")
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Custom name for the deployment")
+	wait.register(cmd)
 
 	return cmd
 }
+
+// checkInstallStatus accepts the statuses of installations that started or,
+// for previews, would start
+func checkInstallStatus(response *api.InstallResponse) error {
+	successStatuses := []string{"success", "initiated", "pending", "deploying", "running", "validated"}
+	if !slices.Contains(successStatuses, response.Status) {
+		return fmt.Errorf("installation failed: %s (status: %s)", response.Message, response.Status)
+	}
+	return nil
+}
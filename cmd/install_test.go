@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// marketplaceAPI serves one app and records the installations
+type marketplaceAPI struct {
+	fakeAPI
+	config    string
+	installed bool
+}
+
+func (m *marketplaceAPI) InstallApp(ctx context.Context, appName, domain, name string, dryRun bool) (*api.InstallResponse, error) {
+	m.installed = m.installed || !dryRun
+	return &api.InstallResponse{
+		AppName:        appName,
+		DeploymentName: appName,
+		Config:         base64.StdEncoding.EncodeToString([]byte(m.config)),
+		Status:         "validated",
+	}, nil
+}
+
+func TestInstallDeploysResolvedVariables(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("N8N_TAG", "1.2.3")
+
+	fake := &marketplaceAPI{config: "name: n8n\ncontainer:\n  image: n8nio/n8n:${N8N_TAG}\n  port: 5678\n"}
+	out, err := runCommand(t, fake, "install", "n8n", "--no-wait")
+	if err != nil {
+		t.Fatalf("install: %v\n%s", err, out)
+	}
+
+	if fake.installed {
+		t.Error("the app was installed with its unresolved configuration")
+	}
+	if fake.deployed == nil || fake.deployed.Container.Image != "n8nio/n8n:1.2.3" {
+		t.Errorf("deployed %+v, want the image with N8N_TAG resolved", fake.deployed)
+	}
+	if saved, _ := os.ReadFile("n8n.yaml"); string(saved) != fake.config {
+		t.Errorf("saved config = %q, want the references kept", saved)
+	}
+}
+
+func TestInstallFailsOnUnsetVariablesBeforeInstalling(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	fake := &marketplaceAPI{config: "name: n8n\ncontainer:\n  image: n8nio/n8n:${N8N_UNSET_TAG}\n"}
+	out, err := runCommand(t, fake, "install", "n8n", "--strict-vars")
+	if _, ok := err.(*config.ValidationError); !ok {
+		t.Fatalf("err = %v, want a *config.ValidationError\n%s", err, out)
+	}
+	if fake.installed || fake.deployed != nil {
+		t.Error("the app was installed despite the unset variable")
+	}
+}
+
+func TestInstallWithoutVariablesUsesThePlatform(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	fake := &marketplaceAPI{config: "name: n8n\ncontainer:\n  image: n8nio/n8n:1.0\n"}
+	out, err := runCommand(t, fake, "install", "n8n", "--no-wait")
+	if err != nil {
+		t.Fatalf("install: %v\n%s", err, out)
+	}
+	if !fake.installed || fake.deployed != nil {
+		t.Errorf("installed %t, deployed %+v, want an installation by the platform", fake.installed, fake.deployed)
+	}
+}
@@ -5,17 +5,13 @@ import (
 	"strconv"
 	"strings"
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(listCmd())
-}
-
-func listCmd() *cobra.Command {
+func listCmd(deps *Deps) *cobra.Command {
 	var (
 		category  string
 		page      int
@@ -41,7 +37,9 @@ Examples:
 
 For advanced usage and more examples, see the documentation.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
@@ -120,23 +118,23 @@ For advanced usage and more examples, see the documentation.`,
 			}
 
 			if len(filters) > 0 {
-				fmt.Printf("%s Listing marketplace apps with filters: %s\n\n", ui.InfoPrint("📋"), strings.Join(filters, ", "))
+				fmt.Fprintf(out, "%s Listing marketplace apps with filters: %s\n\n", ui.InfoPrint("📋"), strings.Join(filters, ", "))
 			} else {
-				fmt.Printf("%s Listing all marketplace apps\n\n", ui.InfoPrint("📋"))
+				fmt.Fprintf(out, "%s Listing all marketplace apps\n\n", ui.InfoPrint("📋"))
 			}
 
 			// Fetch apps from API
-			response, err := apiClient.ListMarketplaceApps(params)
+			response, err := deps.API.ListMarketplaceApps(cmd.Context(), params)
 			if err != nil {
-				return fmt.Errorf("failed to list marketplace apps: %v", err)
+				return fmt.Errorf("failed to list marketplace apps: %w", err)
 			}
 
 			if len(response.Apps) == 0 {
-				fmt.Printf("%s No apps found", ui.WarningPrint("⚠️"))
+				fmt.Fprintf(out, "%s No apps found", ui.WarningPrint("⚠️"))
 				if len(filters) > 0 {
-					fmt.Printf(" with the specified filters")
+					fmt.Fprintf(out, " with the specified filters")
 				}
-				fmt.Println()
+				fmt.Fprintln(out)
 				return nil
 			}
 
@@ -144,43 +142,43 @@ For advanced usage and more examples, see the documentation.`,
 			if total == 0 {
 				total = len(response.Apps)
 			}
-			fmt.Printf("%s Found %d apps", ui.SuccessPrint("✅"), total)
+			fmt.Fprintf(out, "%s Found %d apps", ui.SuccessPrint("✅"), total)
 			if len(response.Apps) < total {
-				fmt.Printf(" (showing %d)", len(response.Apps))
+				fmt.Fprintf(out, " (showing %d)", len(response.Apps))
 			}
-			fmt.Println("\n")
+			fmt.Fprint(out, "\n\n")
 
 			// Display results in a table format
 			for i, app := range response.Apps {
-				fmt.Printf("%s %s\n", color.New(color.Bold, color.FgCyan).Sprint(i+1), color.New(color.Bold).Sprint(app.Name))
-				fmt.Printf("   %s\n", app.Description)
-				fmt.Printf("   Category: %s\n", app.Category)
-				fmt.Printf("   Author: %s\n", app.Author)
-				fmt.Printf("   Version: %s\n", app.Version)
-				fmt.Printf("   Downloads: %d\n", app.Downloads)
-				fmt.Printf("   Rating: %.1f\n", app.Rating)
+				fmt.Fprintf(out, "%s %s\n", color.New(color.Bold, color.FgCyan).Sprint(i+1), color.New(color.Bold).Sprint(app.Name))
+				fmt.Fprintf(out, "   %s\n", app.Description)
+				fmt.Fprintf(out, "   Category: %s\n", app.Category)
+				fmt.Fprintf(out, "   Author: %s\n", app.Author)
+				fmt.Fprintf(out, "   Version: %s\n", app.Version)
+				fmt.Fprintf(out, "   Downloads: %d\n", app.Downloads)
+				fmt.Fprintf(out, "   Rating: %.1f\n", app.Rating)
 
 				if len(app.Tags) > 0 {
-					fmt.Printf("   Tags: %s\n", strings.Join(app.Tags, ", "))
+					fmt.Fprintf(out, "   Tags: %s\n", strings.Join(app.Tags, ", "))
 				}
 
 				if app.Repository != "" {
-					fmt.Printf("   Repository: %s\n", app.Repository)
+					fmt.Fprintf(out, "   Repository: %s\n", app.Repository)
 				}
 
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
 
 			// Show pagination info if applicable
 			if response.Total > len(response.Apps) {
 				totalPages := (response.Total + limit - 1) / limit
-				fmt.Printf("%s Page %d of %d (showing %d-%d of %d total apps)\n",
+				fmt.Fprintf(out, "%s Page %d of %d (showing %d-%d of %d total apps)\n",
 					ui.InfoPrint("📄"), page, totalPages,
 					(page-1)*limit+1, (page-1)*limit+len(response.Apps), response.Total)
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
 
-			fmt.Printf("%s Use 'aja install <app-name>' to install an app\n", ui.InfoPrint("💡"))
+			fmt.Fprintf(out, "%s Use 'aja install <app-name>' to install an app\n", ui.InfoPrint("💡"))
 
 			return nil
 		},
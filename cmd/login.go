@@ -1,61 +1,140 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/google/uuid"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(loginCmd())
-}
+const defaultBrowserLoginTimeout = 2 * time.Minute
+
+func loginCmd(deps *Deps) *cobra.Command {
+	var noBrowser bool
+	var timeout time.Duration
 
-func loginCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Authenticate with DeployAja platform",
+		Long: `Authenticate with DeployAja platform.
+
+By default a browser window is opened to complete the login. Use --no-browser
+on SSH sessions and containers to get a short code to enter on another device.
+The device-code flow is used automatically when stdout is not a terminal.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			sessionCode := uuid.New().String()
-			loginURL := fmt.Sprintf("%s?ses=%s", apiClient.LoginURL, sessionCode)
+			out := cmd.OutOrStdout()
 
-			fmt.Printf("%s Opening browser for authentication...\n", ui.InfoPrint("🔐"))
-			fmt.Printf("If browser doesn't open, visit: %s\n", loginURL)
+			deviceFlow := noBrowser || !ui.IsTerminalWriter(out)
 
-			err := browser.OpenURL(loginURL)
-			if err != nil {
-				fmt.Printf("%s Failed to open browser: %v\n", ui.WarningPrint("⚠"), err)
+			// The device flow is bounded by the code expiry unless a timeout is given
+			if timeout == 0 && !deviceFlow {
+				timeout = defaultBrowserLoginTimeout
 			}
 
-			fmt.Printf("%s Waiting for authentication...\n", ui.InfoPrint("⏳"))
-
-			// Poll for authentication
-			for i := 0; i < 120; i++ { // 2 minutes timeout
-				time.Sleep(1 * time.Second)
+			ctx := cmd.Context()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
 
-				token, err := apiClient.CheckAuth(sessionCode)
-				if err == nil && token != "" {
-					err = config.SaveToken(token)
-					if err != nil {
-						return fmt.Errorf("failed to save token: %v", err)
-					}
+			var token string
+			var err error
+			if deviceFlow {
+				token, err = deviceLogin(ctx, out, deps.API)
+			} else {
+				token, err = browserLogin(ctx, out, deps.API)
+			}
 
-					apiClient.Token = token
-					fmt.Printf("%s Authentication successful!\n", ui.SuccessPrint("✓"))
-					return nil
-				}
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("authentication timeout")
+			}
+			if errors.Is(err, context.Canceled) {
+				return fmt.Errorf("authentication cancelled")
+			}
+			if err != nil {
+				return err
+			}
 
-				if i%5 == 0 {
-					fmt.Print(".")
-				}
+			if err := config.SaveToken(token); err != nil {
+				return fmt.Errorf("failed to save token: %v", err)
 			}
 
-			return fmt.Errorf("authentication timeout")
+			deps.API.SetToken(token)
+			fmt.Fprintf(out, "%s Authentication successful!\n", ui.SuccessPrint("✓"))
+			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Use the device-code flow instead of opening a browser")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Maximum time to wait for authentication (default 2m for browser login, code expiry for --no-browser)")
+
+	return cmd
+}
+
+// browserLogin opens the login page and polls until the session is authenticated
+func browserLogin(ctx context.Context, out io.Writer, client api.DeployAjaAPI) (string, error) {
+	sessionCode := uuid.New().String()
+	loginURL := fmt.Sprintf("%s?ses=%s", client.GetLoginURL(), sessionCode)
+
+	fmt.Fprintf(out, "%s Opening browser for authentication...\n", ui.InfoPrint("🔐"))
+	fmt.Fprintf(out, "If browser doesn't open, visit: %s\n", loginURL)
+	fmt.Fprintf(out, "%s No browser on this machine? Use 'aja login --no-browser'\n", ui.InfoPrint("💡"))
+
+	err := browser.OpenURL(loginURL)
+	if err != nil {
+		fmt.Fprintf(out, "%s Failed to open browser: %v\n", ui.WarningPrint("⚠"), err)
+	}
+
+	fmt.Fprintf(out, "%s Waiting for authentication...\n", ui.InfoPrint("⏳"))
+
+	// Poll for authentication
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out)
+			return "", ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
+
+		token, err := client.CheckAuth(ctx, sessionCode)
+		if err == nil && token != "" {
+			fmt.Fprintln(out)
+			return token, nil
+		}
+
+		if i%5 == 0 {
+			fmt.Fprint(out, ".")
+		}
+	}
+}
+
+// deviceLogin prints a user code to enter on another device and waits for approval
+func deviceLogin(ctx context.Context, out io.Writer, client api.DeployAjaAPI) (string, error) {
+	device, err := client.RequestDeviceCode(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to start device login: %w", err)
+	}
+
+	fmt.Fprintf(out, "%s To authenticate, visit: %s\n", ui.InfoPrint("🔐"), device.VerificationURI)
+	fmt.Fprintf(out, "And enter the code: %s\n", ui.SuccessPrint(device.UserCode))
+	if device.VerificationURIComplete != "" {
+		fmt.Fprintf(out, "Or open: %s\n", device.VerificationURIComplete)
+	}
+	if device.ExpiresIn > 0 {
+		fmt.Fprintf(out, "The code expires in %s\n", time.Duration(device.ExpiresIn)*time.Second)
+	}
+
+	fmt.Fprintf(out, "%s Waiting for authentication...\n", ui.InfoPrint("⏳"))
+
+	return client.WaitForDeviceToken(ctx, device)
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+func logoutCmd(deps *Deps) *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Revoke the token and remove it from this machine",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
+				fmt.Fprintf(out, "%s Not logged in\n", ui.InfoPrint("→"))
+				return nil
+			}
+
+			// Still remove the local token if the server can't be reached
+			if err := deps.API.RevokeToken(cmd.Context()); err != nil {
+				fmt.Fprintf(out, "%s Failed to revoke token server-side: %v\n", ui.WarningPrint("⚠"), err)
+			}
+
+			if err := config.DeleteToken(); err != nil {
+				return fmt.Errorf("failed to remove token: %v", err)
+			}
+			deps.API.SetToken("")
+
+			fmt.Fprintf(out, "%s Logged out of %s\n", ui.SuccessPrint("✓"), deps.context().Name)
+			return nil
+		},
+	}
+}
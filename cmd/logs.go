@@ -1,29 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/signal"
+	"io"
 	"strings"
-	"syscall"
 
-	"deployaja-cli/internal/api"
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(logsCmd())
-}
-
-func logsCmd() *cobra.Command {
+func logsCmd(deps *Deps) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "logs NAME",
 		Short: "View deployment logs",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
@@ -31,14 +28,14 @@ func logsCmd() *cobra.Command {
 			tail, _ := cmd.Flags().GetInt("tail")
 			follow, _ := cmd.Flags().GetBool("follow")
 
-			fmt.Printf("%s Fetching logs for %s...\n", ui.InfoPrint("📝"), name)
+			fmt.Fprintf(out, "%s Fetching logs for %s...\n", ui.InfoPrint("📝"), name)
 
 			if follow {
-				return streamLogs(name, tail)
+				return streamLogs(cmd.Context(), out, deps.API, name, tail)
 			}
 
 			// Regular logs (non-follow mode)
-			logs, err := apiClient.GetLogs(name, tail, false)
+			logs, err := deps.API.GetLogs(cmd.Context(), name, tail, false)
 			if err != nil {
 				return err
 			}
@@ -46,7 +43,7 @@ func logsCmd() *cobra.Command {
 			// Display logs
 			for _, log := range logs {
 				levelColor := ui.GetLogLevelColor(log.Level)
-				fmt.Printf("[%s] %s %s\n",
+				fmt.Fprintf(out, "[%s] %s %s\n",
 					ui.FormatTime(log.Timestamp),
 					levelColor(strings.ToUpper(log.Level)),
 					log.Message)
@@ -62,37 +59,30 @@ func logsCmd() *cobra.Command {
 	return cmd
 }
 
-func streamLogs(name string, tail int) error {
+func streamLogs(ctx context.Context, out io.Writer, client api.DeployAjaAPI, name string, tail int) error {
 	logChan := make(chan api.LogEntry, 100)
 	errorChan := make(chan error, 1)
 
-	// Start streaming in a goroutine
-	go apiClient.GetLogsStream(name, tail, logChan, errorChan)
+	// Start streaming in a goroutine, Ctrl+C cancels ctx and stops it.
+	// Dropped connections are resumed without losing or repeating lines.
+	go client.GetLogsStream(ctx, name, tail, logChan, errorChan)
 
-	// Handle graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	fmt.Fprintf(out, "%s Following logs (press Ctrl+C to stop)...\n", ui.InfoPrint("🔄"))
 
-	fmt.Printf("%s Following logs (press Ctrl+C to stop)...\n", ui.InfoPrint("🔄"))
-
-	for {
-		select {
-		case log := <-logChan:
-			levelColor := ui.GetLogLevelColor(log.Level)
-			fmt.Printf("[%s] %s %s\n",
-				ui.FormatTime(log.Timestamp),
-				levelColor(strings.ToUpper(log.Level)),
-				log.Message)
+	for log := range logChan {
+		levelColor := ui.GetLogLevelColor(log.Level)
+		fmt.Fprintf(out, "[%s] %s %s\n",
+			ui.FormatTime(log.Timestamp),
+			levelColor(strings.ToUpper(log.Level)),
+			log.Message)
+	}
 
-		case err := <-errorChan:
-			if err != nil {
-				return fmt.Errorf("stream error: %v", err)
-			}
-			return nil
+	if err := <-errorChan; err != nil {
+		return fmt.Errorf("stream error: %w", err)
+	}
 
-		case <-sigChan:
-			fmt.Printf("\n%s Stopping log stream...\n", ui.InfoPrint("⏹️"))
-			return nil
-		}
+	if ctx.Err() != nil {
+		fmt.Fprintf(out, "\n%s Stopping log stream...\n", ui.InfoPrint("⏹️"))
 	}
+	return nil
 }
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+)
+
+const (
+	// progressBarWidth is the width of the replica progress bar
+	progressBarWidth = 30
+	// progressEvents is how many of the newest pod events the live view shows
+	progressEvents = 3
+)
+
+// rolloutProgress shows a rollout while waitForRollout waits for it: a view
+// redrawn in place on terminals, and one line per change otherwise so logs
+// of CI jobs stay readable
+type rolloutProgress struct {
+	ctx  context.Context
+	out  io.Writer
+	deps *Deps
+	name string
+	// live is nil when out is not a terminal
+	live *ui.LiveRegion
+
+	// What the line-by-line output already reported
+	lastStatus   string
+	lastReplicas string
+	lastPods     map[string]string
+	seenEvents   map[string]bool
+
+	// recentEvents are the events read for eventsKey, the state of the
+	// deployment they were last read for
+	recentEvents []map[string]interface{}
+	eventsKey    string
+}
+
+func newRolloutProgress(ctx context.Context, out io.Writer, deps *Deps, name string) *rolloutProgress {
+	p := &rolloutProgress{
+		ctx:        ctx,
+		out:        out,
+		deps:       deps,
+		name:       name,
+		lastPods:   map[string]string{},
+		seenEvents: map[string]bool{},
+	}
+	if ui.IsTerminalWriter(out) {
+		p.live = ui.NewLiveRegion(out)
+	}
+	return p
+}
+
+// update shows a new state of the deployment
+func (p *rolloutProgress) update(deployment *api.DeploymentStatus) {
+	events := p.events(deployment)
+	if p.live != nil {
+		p.live.Update(p.render(deployment, events))
+		return
+	}
+	p.printChanges(deployment, events)
+}
+
+// render draws the live view
+func (p *rolloutProgress) render(deployment *api.DeploymentStatus, events []map[string]interface{}) string {
+	var b strings.Builder
+
+	if deployment.Status == "stopped" {
+		fmt.Fprintf(&b, "%s Re-Schedule deployment wait ..\n", ui.WarningPrint("⚠️"))
+	} else {
+		statusColor := ui.GetStatusColor(deployment.Status)
+		fmt.Fprintf(&b, "%s Status: %s\n", ui.InfoPrint("📊"), statusColor(deployment.Status))
+	}
+
+	desired := desiredReplicas(deployment)
+	fmt.Fprintf(&b, "%s %s\n", ui.ProgressBar(deployment.ReadyReplicas, desired, progressBarWidth), replicaSummary(deployment))
+
+	if len(deployment.Pods) > 0 {
+		headers := []string{"POD", "PHASE", "READY", "RESTARTS", "REASON"}
+		var rows [][]string
+		for _, pod := range deployment.Pods {
+			rows = append(rows, []string{
+				pod.Name,
+				ui.GetStatusColor(pod.Phase)(pod.Phase),
+				strconv.FormatBool(pod.Ready),
+				strconv.Itoa(pod.RestartCount),
+				podReason(pod),
+			})
+		}
+		b.WriteString(ui.FormatTable(headers, rows))
+	}
+
+	if len(events) > 0 {
+		fmt.Fprintf(&b, "%s Recent events\n", ui.InfoPrint("📅"))
+		for _, event := range events {
+			fmt.Fprintf(&b, "  %s\n", formatEvent(event))
+		}
+	}
+	return b.String()
+}
+
+// printChanges prints what changed since the last update, one line each
+func (p *rolloutProgress) printChanges(deployment *api.DeploymentStatus, events []map[string]interface{}) {
+	if deployment.Status != p.lastStatus {
+		if deployment.Status == "stopped" {
+			fmt.Fprintf(p.out, "%s Re-Schedule deployment wait ..\n", ui.WarningPrint("⚠️"))
+		} else {
+			fmt.Fprintf(p.out, "%s Status: %s\n", ui.InfoPrint("📊"), deployment.Status)
+		}
+		p.lastStatus = deployment.Status
+	}
+
+	if replicas := replicaSummary(deployment); replicas != p.lastReplicas {
+		fmt.Fprintf(p.out, "   Replicas: %s\n", replicas)
+		p.lastReplicas = replicas
+	}
+
+	for _, pod := range deployment.Pods {
+		state := fmt.Sprintf("%s, ready %t, restarts %d", pod.Phase, pod.Ready, pod.RestartCount)
+		if reason := podReason(pod); reason != "-" {
+			state += " (" + reason + ")"
+		}
+		if p.lastPods[pod.Name] != state {
+			fmt.Fprintf(p.out, "   Pod %s: %s\n", pod.Name, state)
+			p.lastPods[pod.Name] = state
+		}
+	}
+
+	for _, event := range events {
+		key := formatEvent(event)
+		if !p.seenEvents[key] {
+			fmt.Fprintf(p.out, "   Event: %s\n", key)
+			p.seenEvents[key] = true
+		}
+	}
+}
+
+// events returns the newest pod events, oldest first. They are only read
+// again when the status or the pods change or a pod fails, not for every
+// replica becoming ready. Events are extra detail, failing to read them
+// doesn't interrupt the rollout view.
+func (p *rolloutProgress) events(deployment *api.DeploymentStatus) []map[string]interface{} {
+	key := eventsKey(deployment)
+	if key == p.eventsKey {
+		return p.recentEvents
+	}
+
+	describeResp, err := p.deps.API.Describe(p.ctx, p.name)
+	if err != nil {
+		return p.recentEvents
+	}
+	p.eventsKey = key
+
+	events := append([]map[string]interface{}(nil), describeResp.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return fmt.Sprint(events[i]["lastTimestamp"]) < fmt.Sprint(events[j]["lastTimestamp"])
+	})
+	if len(events) > progressEvents {
+		events = events[len(events)-progressEvents:]
+	}
+	p.recentEvents = events
+	return events
+}
+
+// eventsKey identifies the states of a deployment that produce new events:
+// its status, its pods and their failures
+func eventsKey(deployment *api.DeploymentStatus) string {
+	parts := []string{deployment.Status}
+	for _, pod := range deployment.Pods {
+		parts = append(parts, fmt.Sprintf("%s:%s:%d", pod.Name, podReason(pod), pod.RestartCount))
+	}
+	return strings.Join(parts, "|")
+}
+
+// desiredReplicas falls back to the old replica fields like 'aja status'
+func desiredReplicas(deployment *api.DeploymentStatus) int {
+	if deployment.DesiredReplicas > 0 {
+		return deployment.DesiredReplicas
+	}
+	return deployment.Replicas.Desired
+}
+
+func replicaSummary(deployment *api.DeploymentStatus) string {
+	desired := desiredReplicas(deployment)
+	return fmt.Sprintf("%d/%d ready, %d/%d updated", deployment.ReadyReplicas, desired, deployment.UpdatedReplicas, desired)
+}
+
+// podReason explains why a pod isn't ready, from the pod or its containers
+func podReason(pod api.Pod) string {
+	if pod.Reason != "" {
+		return pod.Reason
+	}
+	for _, container := range pod.ContainerStatuses {
+		if container.Reason != "" {
+			return container.Reason
+		}
+	}
+	return "-"
+}
+
+func formatEvent(event map[string]interface{}) string {
+	text := fmt.Sprintf("%v %v: %v", event["type"], event["reason"], event["message"])
+	switch count := fmt.Sprint(event["count"]); count {
+	case "<nil>", "0", "1":
+	default:
+		text += fmt.Sprintf(" (x%s)", count)
+	}
+	return text
+}
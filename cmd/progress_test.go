@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+)
+
+// describeCounter counts the Describe calls of the rollout view
+type describeCounter struct {
+	fakeAPI
+	calls int
+}
+
+func (d *describeCounter) Describe(ctx context.Context, name string) (*api.DescribeResponse, error) {
+	d.calls++
+	return &api.DescribeResponse{}, nil
+}
+
+func TestRolloutProgressReadsEventsOnlyOnChanges(t *testing.T) {
+	fake := &describeCounter{}
+	var out bytes.Buffer
+	progress := newRolloutProgress(context.Background(), &out, &Deps{API: fake}, "shop")
+
+	pod := api.Pod{Name: "shop-1", Phase: "Running"}
+	for ready := 0; ready < 3; ready++ {
+		progress.update(&api.DeploymentStatus{Status: "deploying", DesiredReplicas: 3, ReadyReplicas: ready, Pods: []api.Pod{pod}})
+	}
+	if fake.calls != 1 {
+		t.Errorf("Describe called %d times while only replicas changed, want 1", fake.calls)
+	}
+
+	pod.RestartCount, pod.Reason = 1, "CrashLoopBackOff"
+	progress.update(&api.DeploymentStatus{Status: "deploying", DesiredReplicas: 3, Pods: []api.Pod{pod}})
+	progress.update(&api.DeploymentStatus{Status: "failed", DesiredReplicas: 3, Pods: []api.Pod{pod}})
+	if fake.calls != 3 {
+		t.Errorf("Describe called %d times, want 3 after a pod failure and a status change", fake.calls)
+	}
+}
+
+func TestRolloutProgressWarnsOnceWhenStopped(t *testing.T) {
+	var out bytes.Buffer
+	progress := newRolloutProgress(context.Background(), &out, &Deps{API: &describeCounter{}}, "shop")
+
+	for i := 0; i < 3; i++ {
+		progress.update(&api.DeploymentStatus{Status: "stopped"})
+	}
+	if n := strings.Count(out.String(), "Re-Schedule"); n != 1 {
+		t.Errorf("stopped warning printed %d times, want once:\n%s", n, out.String())
+	}
+}
@@ -8,11 +8,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(publishCmd())
-}
-
-func publishCmd() *cobra.Command {
+func publishCmd(deps *Deps) *cobra.Command {
 	var (
 		name        string
 		description string
@@ -31,42 +27,45 @@ func publishCmd() *cobra.Command {
 		Long: `Publish your app to the Aja marketplace.
 You can specify metadata via flags or interactively.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+			in := cmd.InOrStdin()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
 			// If not provided, try to infer from deployaja.yaml or prompt user
 			if name == "" {
-				fmt.Print("App name: ")
-				fmt.Scanln(&name)
+				fmt.Fprint(out, "App name: ")
+				fmt.Fscanln(in, &name)
 			}
 			if version == "" {
-				fmt.Print("Version: ")
-				fmt.Scanln(&version)
+				fmt.Fprint(out, "Version: ")
+				fmt.Fscanln(in, &version)
 			}
 			if description == "" {
-				fmt.Print("Description: ")
-				fmt.Scanln(&description)
+				fmt.Fprint(out, "Description: ")
+				fmt.Fscanln(in, &description)
 			}
 			if category == "" {
-				fmt.Print("Category: ")
-				fmt.Scanln(&category)
+				fmt.Fprint(out, "Category: ")
+				fmt.Fscanln(in, &category)
 			}
 			if author == "" {
-				fmt.Print("Author: ")
-				fmt.Scanln(&author)
+				fmt.Fprint(out, "Author: ")
+				fmt.Fscanln(in, &author)
 			}
 			if repository == "" {
-				fmt.Print("Repository (optional): ")
-				fmt.Scanln(&repository)
+				fmt.Fprint(out, "Repository (optional): ")
+				fmt.Fscanln(in, &repository)
 			}
 			if image == "" {
-				fmt.Print("Image (optional): ")
-				fmt.Scanln(&image)
+				fmt.Fprint(out, "Image (optional): ")
+				fmt.Fscanln(in, &image)
 			}
 			if tags == "" {
-				fmt.Print("Tags (comma separated, optional): ")
-				fmt.Scanln(&tags)
+				fmt.Fprint(out, "Tags (comma separated, optional): ")
+				fmt.Fscanln(in, &tags)
 			}
 
 			tagList := []string{}
@@ -88,9 +87,10 @@ You can specify metadata via flags or interactively.`,
 				return fmt.Errorf("config file '%s' not found: %v", configFile, err)
 			}
 
-			fmt.Printf("📤 Publishing app '%s' (version: %s)...\n", name, version)
+			fmt.Fprintf(out, "📤 Publishing app '%s' (version: %s)...\n", name, version)
 
-			resp, err := apiClient.PublishApp(
+			resp, err := deps.API.PublishApp(
+				cmd.Context(),
 				name,
 				description,
 				category,
@@ -102,16 +102,16 @@ You can specify metadata via flags or interactively.`,
 				configFile,
 			)
 			if err != nil {
-				return fmt.Errorf("failed to publish app: %v", err)
+				return fmt.Errorf("failed to publish app: %w", err)
 			}
 
-			fmt.Printf("✅ App published! ID: %s\n", resp.ID)
-			fmt.Printf("Status: %s\n", resp.Status)
+			fmt.Fprintf(out, "✅ App published! ID: %s\n", resp.ID)
+			fmt.Fprintf(out, "Status: %s\n", resp.Status)
 			if resp.PublishedAt != "" {
-				fmt.Printf("Published at: %s\n", resp.PublishedAt)
+				fmt.Fprintf(out, "Published at: %s\n", resp.PublishedAt)
 			}
 			if resp.Message != "" {
-				fmt.Printf("Message: %s\n", resp.Message)
+				fmt.Fprintf(out, "Message: %s\n", resp.Message)
 			}
 			return nil
 		},
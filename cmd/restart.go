@@ -3,31 +3,34 @@ package cmd
 import (
 	"fmt"
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(restartCmd())
-}
-
-func restartCmd() *cobra.Command {
+func restartCmd(deps *Deps) *cobra.Command {
+	var wait waitFlags
 	cmd := &cobra.Command{
 		Use:   "restart <DeploymentName>",
 		Short: "Restart a deployment",
 		Long:  "Restart a deployment by deleting and recreating its pods",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
 			deploymentName := args[0]
 
-			fmt.Printf("%s Restarting deployment %s...\n", ui.InfoPrint("🔄"), deploymentName)
+			if err := wait.recordPrevious(cmd, deps, deploymentName); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s Restarting deployment %s...\n", ui.InfoPrint("🔄"), deploymentName)
 
-			response, err := apiClient.Restart(deploymentName)
+			response, err := deps.API.Restart(cmd.Context(), deploymentName)
 			if err != nil {
 				return err
 			}
@@ -37,22 +40,26 @@ func restartCmd() *cobra.Command {
 				return fmt.Errorf("restart failed")
 			}
 
-			fmt.Printf("%s %s\n", ui.SuccessPrint("✓"), response.Data.Message)
-			fmt.Printf("Status: %s\n", response.Data.Status)
-			fmt.Printf("Method: %s\n", response.Data.Method)
+			fmt.Fprintf(out, "%s %s\n", ui.SuccessPrint("✓"), response.Data.Message)
+			fmt.Fprintf(out, "Status: %s\n", response.Data.Status)
+			fmt.Fprintf(out, "Method: %s\n", response.Data.Method)
 
 			// Display rollout status
 			rollout := response.Data.RolloutStatus
-			fmt.Printf("Rollout Status:\n")
-			fmt.Printf("  Generation: %d\n", rollout.Generation)
-			fmt.Printf("  Observed Generation: %d\n", rollout.ObservedGeneration)
-			fmt.Printf("  Replicas: %d\n", rollout.Replicas)
-			fmt.Printf("  Ready Replicas: %d\n", rollout.ReadyReplicas)
-			fmt.Printf("  Updated Replicas: %d\n", rollout.UpdatedReplicas)
-
-			return nil
+			fmt.Fprintf(out, "Rollout Status:\n")
+			fmt.Fprintf(out, "  Generation: %d\n", rollout.Generation)
+			fmt.Fprintf(out, "  Observed Generation: %d\n", rollout.ObservedGeneration)
+			fmt.Fprintf(out, "  Replicas: %d\n", rollout.Replicas)
+			fmt.Fprintf(out, "  Ready Replicas: %d\n", rollout.ReadyReplicas)
+			fmt.Fprintf(out, "  Updated Replicas: %d\n", rollout.UpdatedReplicas)
+
+			if !wait.enabled() {
+				return nil
+			}
+			return waitForRollout(cmd, deps, deploymentName, "Restart", &wait)
 		},
 	}
 
+	wait.register(cmd)
 	return cmd
 }
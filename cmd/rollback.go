@@ -2,41 +2,50 @@ package cmd
 
 import (
 	"fmt"
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/ui"
+
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(rollbackCmd())
-}
-
-func rollbackCmd() *cobra.Command {
-	return &cobra.Command{
+func rollbackCmd(deps *Deps) *cobra.Command {
+	var wait waitFlags
+	cmd := &cobra.Command{
 		Use:   "rollback",
-		Short: "Rollback deployment to previous version",		
+		Short: "Rollback deployment to previous version",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
-			cfg, err := config.LoadDeploymentConfig()
+			cfg, err := deps.loadDeploymentConfig()
 			if err != nil {
 				return err
 			}
 			name := cfg.Name
 
-			fmt.Printf("%s Rolling back %s...\n", ui.InfoPrint("⏪"), name)
+			if err := wait.recordPrevious(cmd, deps, name); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s Rolling back %s...\n", ui.InfoPrint("⏪"), name)
 
-			err = apiClient.Rollback(name)
+			err = deps.API.Rollback(cmd.Context(), name)
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("%s Rollback initiated for %s\n", ui.SuccessPrint("✓"), name)
+			fmt.Fprintf(out, "%s Rollback initiated for %s\n", ui.SuccessPrint("✓"), name)
 
-			return nil
+			if !wait.enabled() {
+				return nil
+			}
+			return waitForRollout(cmd, deps, name, "Rollback", &wait)
 		},
 	}
+
+	wait.register(cmd)
+	return cmd
 }
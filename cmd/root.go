@@ -1,49 +1,194 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
-	"deployaja-cli/internal/api"
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-var (
-	cfgFile   string
-	apiClient *api.APIClient
-)
+var cfgFile string
+
+// Deps are the services shared by the commands. They are injected into the
+// command factories so commands can be driven programmatically and tested
+// with a fake DeployAjaAPI.
+type Deps struct {
+	API api.DeployAjaAPI
+	// Context is the active context, the default context when nil
+	Context *config.Context
+	// Env selects the overlay merged over deployment configs, the context
+	// default when empty
+	Env string
+	// Strict makes unset ${VAR} references without a default in deployment
+	// configs errors
+	Strict bool
+}
+
+// defaultDeps is wired to the configured platform by initConfig
+var defaultDeps = &Deps{}
 
-var rootCmd = &cobra.Command{
-	Use:   "aja",
-	Short: "Deploy applications with managed dependencies",
-	Long: `DeployAja is a CLI tool that simplifies container deployment 
+// rootCmd is the aja command run by Execute, configured from the flags,
+// environment and ~/.deployaja before any command runs
+var rootCmd = newRootCmd(defaultDeps)
+
+// NewRootCmd builds the aja command tree around deps. The configuration
+// files, environment variables and connection flags are not read: commands
+// use deps.API and deps.Context as given, so they can be driven
+// programmatically and tested with a fake DeployAjaAPI.
+func NewRootCmd(deps Deps) *cobra.Command {
+	cmd := newRootCmd(&deps)
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// The flags and arguments were accepted, later failures aren't usage errors
+		cmd.SilenceUsage = true
+		if deps.API == nil {
+			return errors.New("no DeployAjaAPI given to NewRootCmd")
+		}
+		if cmd.Flags().Changed("env") {
+			deps.Env, _ = cmd.Flags().GetString("env")
+		}
+		if cmd.Flags().Changed("strict-vars") {
+			deps.Strict, _ = cmd.Flags().GetBool("strict-vars")
+		}
+		return nil
+	}
+	return cmd
+}
+
+// newRootCmd builds the command tree, every command sharing deps
+func newRootCmd(deps *Deps) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "aja",
+		Short: "Deploy applications with managed dependencies",
+		Long: `DeployAja is a CLI tool that simplifies container deployment 
 with managed dependencies like PostgreSQL, Redis, and more.`,
+		// Execute prints errors once, with the request ID
+		SilenceErrors: true,
+	}
+
+	flags := cmd.PersistentFlags()
+	defaults := api.DefaultRetryPolicy()
+	flags.Int("retries", defaults.MaxRetries, "Number of retries for transient API failures (env DEPLOYAJA_RETRIES, 0 disables)")
+	flags.Duration("retry-max-backoff", defaults.MaxBackoff, "Maximum wait between API retries (env DEPLOYAJA_RETRY_MAX_BACKOFF)")
+	flags.String("context", "", "Name of the context to use (env DEPLOYAJA_CONTEXT)")
+	flags.String("env", "", "Environment overlay merged over the deployment config, e.g. staging for deployaja.staging.yaml (env DEPLOYAJA_ENV)")
+	flags.Bool("strict-vars", false, "Fail on unset ${VAR} references without a default in deployment configs (env DEPLOYAJA_STRICT_VARS)")
+
+	transport := api.DefaultTransportConfig()
+	flags.String("ca-file", "", "PEM file with additional CA certificates to trust (env DEPLOYAJA_CA_FILE)")
+	flags.String("client-cert", "", "PEM client certificate for mutual TLS (env DEPLOYAJA_CLIENT_CERT)")
+	flags.String("client-key", "", "PEM client key for mutual TLS (env DEPLOYAJA_CLIENT_KEY)")
+	flags.Bool("insecure-skip-verify", false, "Skip TLS certificate verification, for testing only (env DEPLOYAJA_INSECURE_SKIP_VERIFY)")
+	flags.Duration("request-timeout", transport.RequestTimeout, "Timeout for a single API request, log streams excluded (env DEPLOYAJA_REQUEST_TIMEOUT)")
+	flags.String("proxy", "", "Proxy URL, or 'direct' to bypass HTTPS_PROXY/HTTP_PROXY (env DEPLOYAJA_PROXY)")
+
+	flags.Bool("debug", false, "Trace API requests and responses with secrets redacted (env DEPLOYAJA_DEBUG)")
+	flags.String("debug-file", "", "Write the debug trace to a file instead of stderr (env DEPLOYAJA_DEBUG_FILE)")
+
+	cmd.AddCommand(
+		authCmd(deps),
+		configCmd(deps),
+		contextCmd(deps),
+		deployCmd(deps),
+		depsCmd(deps),
+		describeCmd(deps),
+		devServerCmd(),
+		dropCmd(deps),
+		envCmd(deps),
+		genCmd(deps),
+		initCmd(),
+		installCmd(deps),
+		listCmd(deps),
+		loginCmd(deps),
+		logoutCmd(deps),
+		logsCmd(deps),
+		planCmd(deps),
+		publishCmd(deps),
+		restartCmd(deps),
+		rollbackCmd(deps),
+		schemaCmd(),
+		searchCmd(deps),
+		statusCmd(deps),
+		upgradeCmd(),
+		validateCmd(deps),
+		versionCmd(),
+		whoamiCmd(deps),
+	)
+	return cmd
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "%s %v\n", ui.ErrorPrint("Error:"), err)
-		os.Exit(1)
+	// Cancel in-flight API calls and polling loops on Ctrl+C or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
+		exitWithError(err)
+	}
+}
+
+// exitWithError prints err and exits with the code matching its failure class
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "%s %v\n", ui.ErrorPrint("Error:"), err)
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+		fmt.Fprintf(os.Stderr, "Request ID: %s\n", apiErr.RequestID)
 	}
+	os.Exit(exitCode(err))
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// The flags and arguments were accepted, later failures aren't usage errors
+		cmd.SilenceUsage = true
+		return initConfig()
+	}
+
+	bindPersistentFlag("retries", "DEPLOYAJA_RETRIES")
+	bindPersistentFlag("retry-max-backoff", "DEPLOYAJA_RETRY_MAX_BACKOFF")
+	viper.BindEnv("credentialStore", "DEPLOYAJA_CREDENTIAL_STORE")
+	bindPersistentFlag("context", "DEPLOYAJA_CONTEXT")
+	bindPersistentFlag("env", "DEPLOYAJA_ENV")
+	bindPersistentFlag("strict-vars", "DEPLOYAJA_STRICT_VARS")
+
+	bindPersistentFlag("ca-file", "DEPLOYAJA_CA_FILE")
+	bindPersistentFlag("client-cert", "DEPLOYAJA_CLIENT_CERT")
+	bindPersistentFlag("client-key", "DEPLOYAJA_CLIENT_KEY")
+	bindPersistentFlag("insecure-skip-verify", "DEPLOYAJA_INSECURE_SKIP_VERIFY")
+	bindPersistentFlag("request-timeout", "DEPLOYAJA_REQUEST_TIMEOUT")
+	bindPersistentFlag("proxy", "DEPLOYAJA_PROXY")
+
+	bindPersistentFlag("debug-file", "DEPLOYAJA_DEBUG_FILE")
+	// AJA_DEBUG was documented before --debug existed
+	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
+	viper.BindEnv("debug", "DEPLOYAJA_DEBUG", "AJA_DEBUG")
 }
 
-func initConfig() {
+// bindPersistentFlag lets a root flag be set from the environment or config.yaml
+func bindPersistentFlag(name, env string) {
+	viper.BindPFlag(name, rootCmd.PersistentFlags().Lookup(name))
+	viper.BindEnv(name, env)
+}
+
+// initConfig wires defaultDeps to the platform of the active context,
+// before any command of rootCmd runs
+func initConfig() error {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
 		home, err := os.UserHomeDir()
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 
 		configPath := filepath.Join(home, config.ConfigDir)
@@ -56,13 +201,84 @@ func initConfig() {
 	viper.AutomaticEnv()
 	viper.ReadInConfig()
 
+	cliConfig, err := config.LoadCLIConfig()
+	if err != nil {
+		return err
+	}
+
+	contextName := viper.GetString("context")
+	activeContext, err := cliConfig.ResolveContext(contextName)
+	if err != nil {
+		if contextName != "" {
+			return err
+		}
+		// A dangling currentContext must not lock users out of 'aja context use'
+		fmt.Fprintf(os.Stderr, "%s %v, falling back to '%s'\n", ui.WarningPrint("Warning:"), err, config.DefaultContext)
+		activeContext = &config.Context{Name: config.DefaultContext}
+	}
+
+	serverURL := contextServerURL(activeContext)
+	apiURL := serverURL
+
+	var store config.CredentialStore
+	if isMockURL(serverURL) {
+		apiURL, store, err = startMockPlatform(serverURL)
+	} else {
+		store, err = config.NewCredentialStore(contextStoreKind(activeContext), activeContext.Name, serverURL)
+	}
+	if err != nil {
+		return err
+	}
+	config.SetCredentialStore(store)
+
 	token := config.LoadToken()
-	apiClient = api.NewApiClient(token)
+	apiClient := api.NewApiClientWithURL(apiURL, token)
+	apiClient.Retry.MaxRetries = viper.GetInt("retries")
+	apiClient.Retry.MaxBackoff = viper.GetDuration("retry-max-backoff")
+
+	transport := api.TransportConfig{
+		CAFile:             viper.GetString("ca-file"),
+		ClientCert:         viper.GetString("client-cert"),
+		ClientKey:          viper.GetString("client-key"),
+		InsecureSkipVerify: viper.GetBool("insecure-skip-verify"),
+		RequestTimeout:     viper.GetDuration("request-timeout"),
+		Proxy:              viper.GetString("proxy"),
+	}
+	if transport.InsecureSkipVerify {
+		fmt.Fprintf(os.Stderr, "%s TLS certificate verification is disabled, the connection to %s is not secure\n", ui.WarningPrint("Warning:"), serverURL)
+	}
+	if err := apiClient.ConfigureTransport(transport); err != nil {
+		return err
+	}
+
+	if debugFile := viper.GetString("debug-file"); debugFile != "" {
+		f, err := os.OpenFile(debugFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open debug file: %v", err)
+		}
+		apiClient.EnableDebug(f)
+	} else if viper.GetBool("debug") {
+		apiClient.EnableDebug(os.Stderr)
+	}
+
+	defaultDeps.API = apiClient
+	defaultDeps.Context = activeContext
+	defaultDeps.Env = viper.GetString("env")
+	defaultDeps.Strict = viper.GetBool("strict-vars")
+	return nil
 }
 
-func ensureAuthenticated() error {
-	if apiClient.Token == "" {
+func (d *Deps) ensureAuthenticated() error {
+	if !d.API.HasToken() {
 		return fmt.Errorf("not authenticated. Run 'aja login' first")
 	}
 	return nil
 }
+
+// context returns the active context
+func (d *Deps) context() *config.Context {
+	if d.Context == nil {
+		return &config.Context{Name: config.DefaultContext}
+	}
+	return d.Context
+}
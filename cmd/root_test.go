@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// fakeAPI answers the calls the tests make, any other call panics
+type fakeAPI struct {
+	api.DeployAjaAPI
+
+	deployments []api.DeploymentStatus
+	deployed    *config.DeploymentConfig
+	dryRun      bool
+	// waited holds the options of the last PollDeploymentStatus
+	waited *api.WaitOptions
+}
+
+func (f *fakeAPI) HasToken() bool { return true }
+
+func (f *fakeAPI) GetStatus(ctx context.Context) (*api.StatusResponse, error) {
+	return &api.StatusResponse{Deployments: f.deployments}, nil
+}
+
+func (f *fakeAPI) Deploy(ctx context.Context, cfg *config.DeploymentConfig, dryRun bool, dockerUsername, dockerPassword, dockerRegistry string) (*api.DeployResponse, error) {
+	f.deployed, f.dryRun = cfg, dryRun
+	return &api.DeployResponse{Status: "validated", Message: "Dry run succeeded"}, nil
+}
+
+func (f *fakeAPI) GetDeployment(ctx context.Context, name string) (*api.DeploymentStatus, error) {
+	for i := range f.deployments {
+		if f.deployments[i].Name == name {
+			deployment := f.deployments[i]
+			return &deployment, nil
+		}
+	}
+	return nil, &api.Error{StatusCode: http.StatusNotFound, Code: "DEPLOYMENT_NOT_FOUND"}
+}
+
+// PollDeploymentStatus reports the rollout as completed right away
+func (f *fakeAPI) PollDeploymentStatus(ctx context.Context, name string, opts api.WaitOptions, onUpdate func(*api.DeploymentStatus) error) (*api.DeploymentStatus, error) {
+	f.waited = &opts
+	return &api.DeploymentStatus{Name: name, Status: "running"}, nil
+}
+
+func runCommand(t *testing.T, fake api.DeployAjaAPI, args ...string) (string, error) {
+	t.Helper()
+	root := NewRootCmd(Deps{API: fake})
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestStatusWithFakeAPI(t *testing.T) {
+	fake := &fakeAPI{deployments: []api.DeploymentStatus{
+		{Name: "shop", Status: "running", URL: "https://shop.deployaja.id", DesiredReplicas: 2, AvailableReplicas: 2, ReadyReplicas: 2},
+	}}
+
+	out, err := runCommand(t, fake, "status")
+	if err != nil {
+		t.Fatalf("status: %v\n%s", err, out)
+	}
+	for _, want := range []string{"shop", "2/2", "https://shop.deployaja.id"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDeployWithFakeAPI(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deployaja.yaml")
+	data := "name: shop\ncontainer:\n  image: acme/shop:1.0\n  port: 8080\nenv:\n  - name: LOG_LEVEL\n    value: info\n"
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeAPI{}
+	out, err := runCommand(t, fake, "deploy", "-f", file, "--dry-run", "--set", "env[LOG_LEVEL].value=debug", "--set", "resources.replicas=3")
+	if err != nil {
+		t.Fatalf("deploy: %v\n%s", err, out)
+	}
+
+	if fake.deployed == nil || !fake.dryRun {
+		t.Fatalf("Deploy was not called as a dry run")
+	}
+	if got := fake.deployed.Env[0].Value; got != "debug" {
+		t.Errorf("LOG_LEVEL = %q, want debug", got)
+	}
+	if got := fake.deployed.Resources.Replicas; got != 3 {
+		t.Errorf("replicas = %d, want 3", got)
+	}
+}
+
+func TestDeployRejectsInvalidConfigBeforeCallingAPI(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deployaja.yaml")
+	if err := os.WriteFile(file, []byte("name: Shop_App\ncontainer:\n  image: acme/shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeAPI{}
+	out, err := runCommand(t, fake, "deploy", "-f", file)
+	if _, ok := err.(*config.ValidationError); !ok {
+		t.Fatalf("err = %v, want a *config.ValidationError\n%s", err, out)
+	}
+	if fake.deployed != nil {
+		t.Error("Deploy was called with an invalid config")
+	}
+}
+
+func TestDeploySetSplitsAssignmentsOnCommas(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deployaja.yaml")
+	if err := os.WriteFile(file, []byte("name: shop\ncontainer:\n  image: acme/shop:1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeAPI{}
+	out, err := runCommand(t, fake, "deploy", "-f", file, "--dry-run", "--set", `resources.replicas=2, env[REGIONS].value=eu\,us`)
+	if err != nil {
+		t.Fatalf("deploy: %v\n%s", err, out)
+	}
+	if replicas := fake.deployed.Resources.Replicas; replicas != 2 {
+		t.Errorf("replicas = %d, want 2", replicas)
+	}
+	if env := fake.deployed.Env; len(env) != 1 || env[0].Value != "eu,us" {
+		t.Errorf("env = %+v, want REGIONS=eu,us", env)
+	}
+}
+
+func TestDeployValidatesNameFlag(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deployaja.yaml")
+	if err := os.WriteFile(file, []byte("name: shop\ncontainer:\n  image: acme/shop:1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeAPI{}
+	out, err := runCommand(t, fake, "deploy", "-f", file, "--name", "Shop_Staging")
+	if _, ok := err.(*config.ValidationError); !ok {
+		t.Fatalf("err = %v, want a *config.ValidationError\n%s", err, out)
+	}
+	if !strings.Contains(out, "shop-staging") || fake.deployed != nil {
+		t.Errorf("want the name rejected with a suggestion before deploying:\n%s", out)
+	}
+
+	out, err = runCommand(t, fake, "deploy", "-f", file, "--dry-run", "--name", "shop-staging")
+	if err != nil {
+		t.Fatalf("deploy: %v\n%s", err, out)
+	}
+	if fake.deployed.Name != "shop-staging" {
+		t.Errorf("name = %q, want shop-staging", fake.deployed.Name)
+	}
+}
+
+func TestRedeployWaitsForTheNewRollout(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deployaja.yaml")
+	if err := os.WriteFile(file, []byte("name: shop\ncontainer:\n  image: acme/shop:2.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	running := api.DeploymentStatus{Name: "shop", Status: "running", LastDeployed: "2026-10-01T10:00:00Z", Pods: []api.Pod{{Name: "shop-r1-0"}}}
+	fake := &fakeAPI{deployments: []api.DeploymentStatus{running}}
+	out, err := runCommand(t, fake, "deploy", "-f", file)
+	if err != nil {
+		t.Fatalf("deploy: %v\n%s", err, out)
+	}
+	if fake.waited == nil || fake.waited.Previous == nil || fake.waited.Previous.LastDeployed != running.LastDeployed {
+		t.Fatalf("waited with %+v, want the running deployment as the previous rollout", fake.waited)
+	}
+
+	// A first deploy has no previous rollout
+	fake = &fakeAPI{}
+	if out, err := runCommand(t, fake, "deploy", "-f", file); err != nil {
+		t.Fatalf("deploy: %v\n%s", err, out)
+	}
+	if fake.waited == nil || fake.waited.Previous != nil {
+		t.Errorf("waited with %+v, want no previous rollout", fake.waited)
+	}
+}
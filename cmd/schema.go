@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+func schemaCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of deployaja.yaml",
+		Long: `Print the JSON Schema of deployaja.yaml, for editors and CI checks.
+
+Files created by 'aja init' point the YAML language server at the published
+schema, so VS Code and other editors autocomplete and validate them. For
+other files, add this first line:
+
+  ` + config.SchemaModeline,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			data, err := config.MarshalDeploymentConfigSchema()
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err = out.Write(data)
+				return err
+			}
+
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				return fmt.Errorf("failed to write schema: %v", err)
+			}
+			fmt.Fprintf(out, "%s Schema written to %s\n", ui.SuccessPrint("✓"), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the schema to a file instead of stdout")
+	return cmd
+}
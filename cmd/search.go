@@ -4,17 +4,13 @@ import (
 	"fmt"
 	"strings"
 
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(searchCmd())
-}
-
-func searchCmd() *cobra.Command {
+func searchCmd(deps *Deps) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "search [QUERY]",
 		Short: "Search for apps in the marketplace",
@@ -22,49 +18,51 @@ func searchCmd() *cobra.Command {
 You can search by app name, description, category, or tags.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
 			query := args[0]
 
-			fmt.Printf("%s Searching for: %s\n\n", ui.InfoPrint("🔍"), query)
+			fmt.Fprintf(out, "%s Searching for: %s\n\n", ui.InfoPrint("🔍"), query)
 
 			// Search apps via API
-			response, err := apiClient.SearchApps(query)
+			response, err := deps.API.SearchApps(cmd.Context(), query)
 			if err != nil {
-				return fmt.Errorf("failed to search apps: %v", err)
+				return fmt.Errorf("failed to search apps: %w", err)
 			}
 
 			if len(response.Apps) == 0 {
-				fmt.Printf("%s No apps found matching '%s'\n", ui.WarningPrint("⚠️"), query)
+				fmt.Fprintf(out, "%s No apps found matching '%s'\n", ui.WarningPrint("⚠️"), query)
 				return nil
 			}
 
-			fmt.Printf("%s Found %d apps\n\n", ui.SuccessPrint("✅"), response.Total)
+			fmt.Fprintf(out, "%s Found %d apps\n\n", ui.SuccessPrint("✅"), response.Total)
 
 			// Display results in a table format
 			for i, app := range response.Apps {
-				fmt.Printf("%s %s\n", color.New(color.Bold, color.FgCyan).Sprint(i+1), color.New(color.Bold).Sprint(app.Name))
-				fmt.Printf("   %s\n", app.Description)
-				fmt.Printf("   Category: %s\n", app.Category)
-				fmt.Printf("   Author: %s\n", app.Author)
-				fmt.Printf("   Version: %s\n", app.Version)
-				fmt.Printf("   Downloads: %d\n", app.Downloads)
-				fmt.Printf("   Rating: %.1f/5.0\n", app.Rating)
+				fmt.Fprintf(out, "%s %s\n", color.New(color.Bold, color.FgCyan).Sprint(i+1), color.New(color.Bold).Sprint(app.Name))
+				fmt.Fprintf(out, "   %s\n", app.Description)
+				fmt.Fprintf(out, "   Category: %s\n", app.Category)
+				fmt.Fprintf(out, "   Author: %s\n", app.Author)
+				fmt.Fprintf(out, "   Version: %s\n", app.Version)
+				fmt.Fprintf(out, "   Downloads: %d\n", app.Downloads)
+				fmt.Fprintf(out, "   Rating: %.1f/5.0\n", app.Rating)
 
 				if len(app.Tags) > 0 {
-					fmt.Printf("   Tags: %s\n", strings.Join(app.Tags, ", "))
+					fmt.Fprintf(out, "   Tags: %s\n", strings.Join(app.Tags, ", "))
 				}
 
 				if app.Repository != "" {
-					fmt.Printf("   Repository: %s\n", app.Repository)
+					fmt.Fprintf(out, "   Repository: %s\n", app.Repository)
 				}
 
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
 
-			fmt.Printf("%s Use 'aja install <app-name>' to install an app\n", ui.InfoPrint("💡"))
+			fmt.Fprintf(out, "%s Use 'aja install <app-name>' to install an app\n", ui.InfoPrint("💡"))
 
 			return nil
 		},
@@ -3,17 +3,13 @@ package cmd
 import (
 	"fmt"
 
-	"deployaja-cli/internal/api"
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(statusCmd())
-}
-
-func statusCmd() *cobra.Command {
+func statusCmd(deps *Deps) *cobra.Command {
 	var detailed bool
 
 	cmd := &cobra.Command{
@@ -21,21 +17,23 @@ func statusCmd() *cobra.Command {
 		Aliases: []string{"ls"},
 		Short:   "Check deployment status and health",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := ensureAuthenticated(); err != nil {
+			out := cmd.OutOrStdout()
+
+			if err := deps.ensureAuthenticated(); err != nil {
 				return err
 			}
 
-			response, err := apiClient.GetStatus()
+			response, err := deps.API.GetStatus(cmd.Context())
 			if err != nil {
 				return err
 			}
 
 			if len(response.Deployments) == 0 {
-				fmt.Printf("%s No deployments found\n", ui.WarningPrint("⚠"))
+				fmt.Fprintf(out, "%s No deployments found\n", ui.WarningPrint("⚠"))
 				return nil
 			}
 
-			fmt.Printf("%s Deployment Status\n\n", ui.InfoPrint("📊"))
+			fmt.Fprintf(out, "%s Deployment Status\n\n", ui.InfoPrint("📊"))
 
 			// Prepare table data
 			headers := []string{"NAME", "STATUS", "REPLICAS", "READY", "URL", "LAST DEPLOYED"}
@@ -73,15 +71,15 @@ func statusCmd() *cobra.Command {
 			}
 
 			// Print table
-			fmt.Print(ui.FormatTable(headers, rows))
+			fmt.Fprint(out, ui.FormatTable(headers, rows))
 
 			// Show detailed pod information if requested or if there are issues
 			if detailed || hasIssues(response.Deployments) {
-				fmt.Printf("\n%s Pod Details\n\n", ui.InfoPrint("🔍"))
+				fmt.Fprintf(out, "\n%s Pod Details\n\n", ui.InfoPrint("🔍"))
 
 				for _, deployment := range response.Deployments {
 					if len(deployment.Pods) > 0 {
-						fmt.Printf("%s %s\n", ui.InfoPrint("📦"), deployment.Name)
+						fmt.Fprintf(out, "%s %s\n", ui.InfoPrint("📦"), deployment.Name)
 
 						// Pod table
 						podHeaders := []string{"POD NAME", "STATUS", "READY", "RESTARTS", "AGE"}
@@ -112,23 +110,23 @@ func statusCmd() *cobra.Command {
 							})
 						}
 
-						fmt.Print(ui.FormatTable(podHeaders, podRows))
+						fmt.Fprint(out, ui.FormatTable(podHeaders, podRows))
 
 						// Show container details for problematic pods
 						for _, pod := range deployment.Pods {
 							if !pod.Ready || pod.RestartCount > 0 || pod.Status == "CRASH_LOOP" || pod.Status == "FAILED" || pod.Status == "ERROR" {
-								fmt.Printf("\n%s Pod: %s\n", ui.WarningPrint("⚠"), pod.Name)
+								fmt.Fprintf(out, "\n%s Pod: %s\n", ui.WarningPrint("⚠"), pod.Name)
 
 								if pod.Reason != "" {
-									fmt.Printf("  Reason: %s\n", ui.ErrorPrint(pod.Reason))
+									fmt.Fprintf(out, "  Reason: %s\n", ui.ErrorPrint(pod.Reason))
 								}
 
 								if pod.Message != "" {
-									fmt.Printf("  Message: %s\n", ui.ErrorPrint(pod.Message))
+									fmt.Fprintf(out, "  Message: %s\n", ui.ErrorPrint(pod.Message))
 								}
 
 								if len(pod.ContainerStatuses) > 0 {
-									fmt.Printf("  Containers:\n")
+									fmt.Fprintf(out, "  Containers:\n")
 									for _, container := range pod.ContainerStatuses {
 										statusIcon := "✓"
 										statusColor := ui.SuccessPrint
@@ -137,24 +135,24 @@ func statusCmd() *cobra.Command {
 											statusColor = ui.ErrorPrint
 										}
 
-										fmt.Printf("    %s %s: %s", statusColor(statusIcon), container.Name, container.State)
+										fmt.Fprintf(out, "    %s %s: %s", statusColor(statusIcon), container.Name, container.State)
 
 										if container.RestartCount > 0 {
-											fmt.Printf(" (restarts: %s)", ui.WarningPrint(fmt.Sprintf("%d", container.RestartCount)))
+											fmt.Fprintf(out, " (restarts: %s)", ui.WarningPrint(fmt.Sprintf("%d", container.RestartCount)))
 										}
 
 										if container.Reason != "" {
-											fmt.Printf(" - %s", ui.ErrorPrint(container.Reason))
+											fmt.Fprintf(out, " - %s", ui.ErrorPrint(container.Reason))
 										}
 
-										fmt.Printf("\n")
+										fmt.Fprintf(out, "\n")
 
 										if container.Message != "" {
-											fmt.Printf("      %s\n", ui.ErrorPrint(container.Message))
+											fmt.Fprintf(out, "      %s\n", ui.ErrorPrint(container.Message))
 										}
 									}
 								}
-								fmt.Printf("\n")
+								fmt.Fprintf(out, "\n")
 							}
 						}
 					}
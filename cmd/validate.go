@@ -2,33 +2,46 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/ui"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(validateCmd())
-}
+func validateCmd(deps *Deps) *cobra.Command {
+	var offline bool
+	var overrides overrideFlags
 
-func validateCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "validate",
 		Short: "Validate deployaja.yaml configuration",
+		Long: `Validate deployaja.yaml configuration.
+
+The file is checked locally first: unknown or misspelled keys, values of the
+wrong type, invalid names, ports, replicas and resource quantities are
+reported with file:line:column. The platform then validates what it can
+only check itself, unless --offline is set.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.LoadDeploymentConfig()
+			out := cmd.OutOrStdout()
+
+			cfg, err := loadValidatedConfig(out, deps.deploymentConfigFile(""), overrides.options(deps))
 			if err != nil {
 				return err
 			}
 
+			if offline {
+				fmt.Fprintf(out, "%s Configuration is valid (offline checks only)\n", ui.SuccessPrint("✓"))
+				return nil
+			}
+
 			// Use the global API client with proper authentication
 			// Call API to validate configuration
-			validateResp, err := apiClient.Validate(cfg)
+			validateResp, err := deps.API.Validate(cmd.Context(), cfg)
 			if err != nil {
 				// If API validation fails, show the error
-				return fmt.Errorf("validation failed: %v", err)
+				return fmt.Errorf("validation failed: %w", err)
 			}
 
 			if !validateResp.Valid {
@@ -36,17 +49,82 @@ func validateCmd() *cobra.Command {
 			}
 
 			// Configuration is valid
-			fmt.Printf("%s Configuration is valid\n", ui.SuccessPrint("✓"))
+			fmt.Fprintf(out, "%s Configuration is valid\n", ui.SuccessPrint("✓"))
 
 			// Show any warnings if present
 			if len(validateResp.Warnings) > 0 {
-				fmt.Println("\nWarnings:")
+				fmt.Fprintln(out, "\nWarnings:")
 				for _, warning := range validateResp.Warnings {
-					fmt.Printf("%s %s\n", ui.WarningPrint("⚠"), warning)
+					fmt.Fprintf(out, "%s %s\n", ui.WarningPrint("⚠"), warning)
 				}
 			}
 
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&offline, "offline", false, "Only run the local checks, without contacting the platform")
+	overrides.register(cmd)
+	return cmd
+}
+
+// overrideFlags are the flags of commands that load a deployment config and
+// let fields be overridden
+type overrideFlags struct {
+	values    []string
+	set       []string
+	setString []string
+	setFile   []string
+}
+
+func (o *overrideFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&o.values, "values", nil, "YAML file merged over the config like an overlay (can be repeated)")
+	cmd.Flags().StringArrayVar(&o.set, "set", nil, "Set fields by path, e.g. resources.replicas=3,env[LOG_LEVEL].value=debug; \\, keeps a comma in a value (can be repeated)")
+	cmd.Flags().StringArrayVar(&o.setString, "set-string", nil, "Set fields to strings, kept as is even if they look like numbers (can be repeated)")
+	cmd.Flags().StringArrayVar(&o.setFile, "set-file", nil, "Set fields to the contents of files, path=file (can be repeated)")
+}
+
+// options returns what is applied over the deployment config: the overlay
+// of the environment, then the values files and the overrides
+func (o *overrideFlags) options(deps *Deps) config.LoadOptions {
+	return config.LoadOptions{
+		Env:       deps.deploymentEnv(),
+		Values:    o.values,
+		Set:       o.set,
+		SetString: o.setString,
+		SetFile:   o.setFile,
+		Strict:    deps.Strict,
+	}
+}
+
+// loadValidatedConfig checks a deployment configuration with the overlay,
+// values files and overrides of opts applied locally, printing every problem
+// found, and returns it when it has no errors. It runs before any API call so
+// mistakes are reported with their position in the file.
+func loadValidatedConfig(out io.Writer, path string, opts config.LoadOptions) (*config.DeploymentConfig, error) {
+	cfg, diagnostics, err := config.LoadValidatedDeploymentConfig(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := reportDiagnostics(out, diagnostics); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// reportDiagnostics prints diagnostics and returns a *config.ValidationError
+// when there are errors among them
+func reportDiagnostics(out io.Writer, diagnostics []config.Diagnostic) error {
+	for _, d := range diagnostics {
+		if d.Severity == config.SeverityError {
+			fmt.Fprintf(out, "%s %s\n", ui.ErrorPrint("✗"), d)
+		} else {
+			fmt.Fprintf(out, "%s %s\n", ui.WarningPrint("⚠"), d)
+		}
+	}
+	if config.HasErrors(diagnostics) {
+		return &config.ValidationError{Diagnostics: diagnostics}
+	}
+	return nil
 }
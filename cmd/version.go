@@ -3,22 +3,22 @@ package cmd
 import (
 	"fmt"
 
-	"deployaja-cli/internal/version"
+	"github.com/deployaja/deployaja-cli/internal/version"
 
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(versionCmd)
-}
+func versionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Show the CLI version",
+		Run: func(cmd *cobra.Command, args []string) {
+			out := cmd.OutOrStdout()
 
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Show the CLI version",
-	Run: func(cmd *cobra.Command, args []string) {
-		versionStr := version.GetVersion()
-		fmt.Printf("DeployAja CLI version: %s\n", versionStr)
-	},
+			versionStr := version.GetVersion()
+			fmt.Fprintf(out, "DeployAja CLI version: %s\n", versionStr)
+		},
+	}
 }
 
 // ReadVersionFromFile returns the current CLI version
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// waitFlags are the flags of commands that start a rollout and wait for it
+type waitFlags struct {
+	wait         bool
+	noWait       bool
+	timeout      time.Duration
+	pollInterval time.Duration
+	finalStates  []string
+
+	// previous is the state of the deployment before the command started
+	// the rollout, see api.WaitOptions.Previous
+	previous *api.DeploymentStatus
+}
+
+func (w *waitFlags) register(cmd *cobra.Command) {
+	defaults := api.DefaultWaitOptions()
+	cmd.Flags().BoolVar(&w.wait, "wait", true, "Wait for the rollout to finish")
+	cmd.Flags().BoolVar(&w.noWait, "no-wait", false, "Return as soon as the rollout started")
+	cmd.Flags().DurationVar(&w.timeout, "timeout", defaults.Timeout, "Maximum time to wait for the rollout")
+	cmd.Flags().DurationVar(&w.pollInterval, "poll-interval", 0, "Fixed interval between status polls when streaming is unavailable (0 backs off between 2s and 30s)")
+	cmd.Flags().StringSliceVar(&w.finalStates, "final-states", defaults.FinalStates, "Deployment states that end the wait")
+}
+
+// enabled reports whether the command should wait for the rollout
+func (w *waitFlags) enabled() bool {
+	return w.wait && !w.noWait
+}
+
+// recordPrevious reads the state of a deployment before a rollout of it is
+// started, so the wait isn't ended by the final state of the last rollout.
+// A deployment that doesn't exist yet has no previous rollout.
+func (w *waitFlags) recordPrevious(cmd *cobra.Command, deps *Deps, name string) error {
+	if !w.enabled() {
+		return nil
+	}
+
+	previous, err := deps.API.GetDeployment(cmd.Context(), name)
+	if errors.Is(err, api.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	w.previous = previous
+	return nil
+}
+
+func (w *waitFlags) options() api.WaitOptions {
+	opts := api.DefaultWaitOptions()
+	opts.Timeout = w.timeout
+	opts.PollInterval = w.pollInterval
+	opts.FinalStates = w.finalStates
+	opts.Previous = w.previous
+	return opts
+}
+
+// waitForRollout watches a deployment until it reaches a final state and
+// reports the outcome, showing the progress of the rollout meanwhile. A
+// crash-looping pod fails the rollout right away. action names the operation
+// in messages, e.g. "Deployment". The returned error matches
+// api.ErrRolloutFailed, api.ErrRolloutTimeout or api.ErrRolloutUnknown so the
+// exit code tells them apart.
+func waitForRollout(cmd *cobra.Command, deps *Deps, name, action string, flags *waitFlags) error {
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "%s Waiting for %s to complete...\n", ui.InfoPrint("🔍"), name)
+
+	progress := newRolloutProgress(cmd.Context(), out, deps, name)
+	onUpdate := func(deployment *api.DeploymentStatus) error {
+		progress.update(deployment)
+
+		// Fail fast instead of waiting out the timeout
+		if pod := api.CrashLoopingPod(deployment); pod != nil {
+			return fmt.Errorf("%w: pod %s is crash-looping after %d restarts", api.ErrRolloutFailed, pod.Name, pod.RestartCount)
+		}
+		return nil
+	}
+
+	finalDeployment, err := deps.API.PollDeploymentStatus(cmd.Context(), name, flags.options(), onUpdate)
+	if errors.Is(err, api.ErrRolloutFailed) {
+		fmt.Fprintf(out, "%s Use 'aja describe %s' or 'aja logs %s' for more details\n", ui.InfoPrint("💡"), name, name)
+		return fmt.Errorf("%s of %s: %w", action, name, err)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "%s You can check the status manually using: aja status\n", ui.InfoPrint("💡"))
+		if errors.Is(err, api.ErrRolloutTimeout) {
+			return err
+		}
+		return fmt.Errorf("%w: failed to monitor %s: %w", api.ErrRolloutUnknown, name, err)
+	}
+
+	if err := api.RolloutOutcome(finalDeployment); err != nil {
+		fmt.Fprintf(out, "%s Use 'aja describe %s' for more details\n", ui.InfoPrint("💡"), name)
+		return fmt.Errorf("%s of %s: %w", action, name, err)
+	}
+
+	fmt.Fprintf(out, "%s %s completed successfully!\n", ui.SuccessPrint("🎉"), action)
+	if finalDeployment.URL != "" {
+		fmt.Fprintf(out, "%s Access your application at: %s\n", ui.InfoPrint("🌐"), finalDeployment.URL)
+	}
+	return nil
+}
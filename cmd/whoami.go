@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+func whoamiCmd(deps *Deps) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:          "whoami",
+		Short:        "Show the authenticated account and token expiry",
+		SilenceUsage: true,
+		Long: `Show the authenticated account and token expiry.
+
+The token is inspected locally, no API call or refresh is made. The command
+exits non-zero when not authenticated or when the token has expired, so
+scripts can use it to check the auth state.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid output format '%s' (expected text or json)", output)
+			}
+
+			if err := deps.ensureAuthenticated(); err != nil {
+				if output == "json" {
+					printJSON(out, map[string]interface{}{"valid": false, "context": deps.context().Name})
+				}
+				return fmt.Errorf("%w: %v", api.ErrUnauthorized, err)
+			}
+
+			info := deps.API.GetTokenInfo()
+			info["context"] = deps.context().Name
+
+			if output == "json" {
+				printJSON(out, info)
+			} else {
+				printTokenInfo(out, info)
+			}
+
+			if valid, _ := info["valid"].(bool); !valid {
+				return fmt.Errorf("%w: token expired or unreadable, run 'aja login'", api.ErrUnauthorized)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func printTokenInfo(out io.Writer, info map[string]interface{}) {
+	if errMsg, ok := info["error"]; ok {
+		fmt.Fprintf(out, "%s %v\n", ui.WarningPrint("⚠"), errMsg)
+		return
+	}
+
+	fmt.Fprintf(out, "Context:     %v\n", info["context"])
+	if info["type"] == api.TokenTypeAPIKey {
+		fmt.Fprintf(out, "Token type:  API key (expiry is checked by the server)\n")
+		return
+	}
+
+	fmt.Fprintf(out, "Email:       %v\n", info["email"])
+	fmt.Fprintf(out, "Subject:     %v\n", info["subject"])
+	fmt.Fprintf(out, "Issued at:   %v\n", ui.FormatTime(fmt.Sprint(info["issued_at"])))
+	expiresAt, hasExpiry := info["expires_at"]
+	if !hasExpiry {
+		fmt.Fprintf(out, "Expires at:  never\n")
+	} else {
+		fmt.Fprintf(out, "Expires at:  %v\n", ui.FormatTime(fmt.Sprint(expiresAt)))
+	}
+
+	if expired, _ := info["expired"].(bool); expired {
+		fmt.Fprintf(out, "Status:      %s\n", ui.ErrorPrint("✗ Expired"))
+	} else {
+		fmt.Fprintf(out, "Status:      %s", ui.SuccessPrint("✓ Valid"))
+		if hasExpiry {
+			fmt.Fprintf(out, " (expires in %v)", info["time_to_expiry"])
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+func printJSON(out io.Writer, v interface{}) {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Fprintln(out, string(data))
+}
@@ -1,10 +1,11 @@
 package api
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,8 +14,9 @@ import (
 	"strings"
 	"time"
 
-	"deployaja-cli/internal/config"
-	"deployaja-cli/internal/version"
+	"github.com/deployaja/deployaja-cli/internal/config"
+	"github.com/deployaja/deployaja-cli/internal/sse"
+	"github.com/deployaja/deployaja-cli/internal/version"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,36 +29,67 @@ type JWTClaims struct {
 	ExpiresAt int64  `json:"exp"`
 }
 
+// Token types recognised by the client
+const (
+	// TokenTypeJWT is a short-lived session token with a client-readable exp claim
+	TokenTypeJWT = "jwt"
+	// TokenTypeAPIKey is an opaque long-lived service-account key, validated only server-side
+	TokenTypeAPIKey = "api_key"
+)
+
 // APIClient handles all API communications
 type APIClient struct {
 	BaseURL    string
 	LoginURL   string
 	HTTPClient *http.Client
 	Token      string
+	TokenType  string
 	Claims     *JWTClaims
+	Retry      RetryPolicy
+	Watch      WatchPolicy
+
+	// Cache enables conditional requests in GetDeployment, nil disables it
+	Cache *DeploymentCache
+
+	// UserAgent is sent with every request when set
+	UserAgent string
+
+	// TokenStore persists refreshed tokens. update receives the stored token
+	// and returns the one to store. When nil, tokens are only kept in memory.
+	TokenStore func(ctx context.Context, update func(current string) (string, error)) error
 }
 
-func NewApiClient(token string) *APIClient {
-	baseUrl := os.Getenv("DEPLOYAJA_API_URL")
-	if baseUrl == "" {
-		baseUrl = "https://deployaja.id"
+// ServerURL returns the platform URL from DEPLOYAJA_API_URL or the default
+func ServerURL() string {
+	if baseUrl := os.Getenv("DEPLOYAJA_API_URL"); baseUrl != "" {
+		return baseUrl
 	}
+	return "https://deployaja.id"
+}
+
+func NewApiClient(token string) *APIClient {
+	return NewApiClientWithURL(ServerURL(), token)
+}
+
+// NewApiClientWithURL creates a client for the platform at baseUrl
+func NewApiClientWithURL(baseUrl, token string) *APIClient {
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
 
 	client := &APIClient{
 		BaseURL:  baseUrl + "/api/v1",
 		LoginURL: baseUrl + "/login",
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: DefaultTransportConfig().RequestTimeout,
 		},
-		Token: token,
+		Retry:      DefaultRetryPolicy(),
+		Watch:      DefaultWatchPolicy(),
+		Cache:      NewDeploymentCache(),
+		TokenStore: config.UpdateToken,
 	}
 
 	// Parse JWT claims if token is provided
 	if token != "" {
-		claims, err := client.parseJWTClaims(token)
-		if err == nil {
-			client.Claims = claims
-		}
+		client.SetToken(token)
 	}
 
 	return client
@@ -90,11 +123,38 @@ func (c *APIClient) parseJWTClaims(token string) (*JWTClaims, error) {
 	return &claims, nil
 }
 
-// IsTokenExpired checks if the current token is expired
+// HasToken reports whether a token is configured, without checking its validity
+func (c *APIClient) HasToken() bool {
+	return c.Token != ""
+}
+
+// GetBaseURL returns the API endpoint, including the /api/v1 prefix
+func (c *APIClient) GetBaseURL() string {
+	return c.BaseURL
+}
+
+// GetLoginURL returns the browser login page
+func (c *APIClient) GetLoginURL() string {
+	return c.LoginURL
+}
+
+// IsAPIKey reports whether the current token is an opaque service-account key
+func (c *APIClient) IsAPIKey() bool {
+	return c.TokenType == TokenTypeAPIKey
+}
+
+// IsTokenExpired checks if the current token is expired. API keys and JWTs
+// without an exp claim are never considered expired client-side.
 func (c *APIClient) IsTokenExpired() bool {
+	if c.IsAPIKey() {
+		return false
+	}
 	if c.Claims == nil {
 		return true
 	}
+	if c.Claims.ExpiresAt == 0 {
+		return false
+	}
 
 	// Check if token expires within the next 5 minutes (buffer for refresh)
 	expiryTime := time.Unix(c.Claims.ExpiresAt, 0)
@@ -103,6 +163,15 @@ func (c *APIClient) IsTokenExpired() bool {
 
 // GetTokenInfo returns information about the current token
 func (c *APIClient) GetTokenInfo() map[string]interface{} {
+	if c.IsAPIKey() {
+		// Opaque keys carry no claims, only the server knows their owner and expiry
+		return map[string]interface{}{
+			"valid":   true,
+			"type":    TokenTypeAPIKey,
+			"expired": false,
+		}
+	}
+
 	if c.Claims == nil {
 		return map[string]interface{}{
 			"valid": false,
@@ -110,64 +179,166 @@ func (c *APIClient) GetTokenInfo() map[string]interface{} {
 		}
 	}
 
-	expiryTime := time.Unix(c.Claims.ExpiresAt, 0)
-	issuedTime := time.Unix(c.Claims.IssuedAt, 0)
+	info := map[string]interface{}{
+		"valid":     !c.IsTokenExpired(),
+		"type":      TokenTypeJWT,
+		"subject":   c.Claims.Subject,
+		"email":     c.Claims.Email,
+		"issued_at": time.Unix(c.Claims.IssuedAt, 0).Format(time.RFC3339),
+		"expired":   c.IsTokenExpired(),
+	}
 
-	return map[string]interface{}{
-		"valid":          !c.IsTokenExpired(),
-		"subject":        c.Claims.Subject,
-		"email":          c.Claims.Email,
-		"issued_at":      issuedTime.Format(time.RFC3339),
-		"expires_at":     expiryTime.Format(time.RFC3339),
-		"expired":        c.IsTokenExpired(),
-		"time_to_expiry": time.Until(expiryTime).String(),
+	if c.Claims.ExpiresAt != 0 {
+		expiryTime := time.Unix(c.Claims.ExpiresAt, 0)
+		info["expires_at"] = expiryTime.Format(time.RFC3339)
+		info["time_to_expiry"] = time.Until(expiryTime).Round(time.Second).String()
 	}
+
+	return info
 }
 
 // RefreshToken attempts to refresh the current token
-func (c *APIClient) RefreshToken() error {
+func (c *APIClient) RefreshToken(ctx context.Context) error {
 	if c.Token == "" {
 		return fmt.Errorf("no token to refresh")
 	}
+	if c.IsAPIKey() {
+		return fmt.Errorf("API keys can't be refreshed, create a new one with 'aja auth keys create'")
+	}
 
-	resp, err := c.makeRequest("POST", c.BaseURL+"/auth/refresh", nil)
+	return c.updateToken(ctx, func(string) (string, error) {
+		return c.refreshToken(ctx)
+	})
+}
+
+// refreshToken exchanges the current token for a new one. Callers must hold
+// the config state lock so concurrent processes don't race on the refresh.
+func (c *APIClient) refreshToken(ctx context.Context) (string, error) {
+	resp, err := c.makeRequest(ctx, "POST", c.BaseURL+"/auth/refresh", nil)
 	if err != nil {
-		return fmt.Errorf("failed to refresh token: %v", err)
+		return "", fmt.Errorf("failed to refresh token: %w", err)
 	}
 	defer resp.Body.Close()
 
-	var refreshResp struct {
-		Token string `json:"token"`
-	}
-
+	var refreshResp RefreshResponse
 	if err := json.NewDecoder(resp.Body).Decode(&refreshResp); err != nil {
-		return fmt.Errorf("failed to decode refresh response: %v", err)
+		return "", fmt.Errorf("failed to decode refresh response: %v", err)
 	}
 
 	// Update token and claims
-	c.Token = refreshResp.Token
-	claims, err := c.parseJWTClaims(refreshResp.Token)
-	if err == nil {
-		c.Claims = claims
+	c.SetToken(refreshResp.Token)
+
+	return refreshResp.Token, nil
+}
+
+// RevokeToken invalidates the current token server-side
+func (c *APIClient) RevokeToken(ctx context.Context) error {
+	if c.Token == "" {
+		return fmt.Errorf("no token to revoke")
 	}
 
-	// Save the new token
-	if err := config.SaveToken(refreshResp.Token); err != nil {
-		return fmt.Errorf("failed to save refreshed token: %v", err)
+	resp, err := c.makeRequest(ctx, "POST", c.BaseURL+"/auth/revoke", nil)
+	if err != nil {
+		return err
 	}
+	resp.Body.Close()
 
 	return nil
 }
 
+// CreateAPIKey creates a scoped service-account key for CI pipelines
+func (c *APIClient) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	resp, err := c.makeAuthenticatedRequest(ctx, "POST", c.BaseURL+"/auth/keys", req, withIdempotencyKey())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var keyResp CreateAPIKeyResponse
+	err = json.NewDecoder(resp.Body).Decode(&keyResp)
+	return &keyResp, err
+}
+
+// ListAPIKeys lists the service-account keys of the current account
+func (c *APIClient) ListAPIKeys(ctx context.Context) (*APIKeysResponse, error) {
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", c.BaseURL+"/auth/keys", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var keysResp APIKeysResponse
+	err = json.NewDecoder(resp.Body).Decode(&keysResp)
+	return &keysResp, err
+}
+
+// RevokeAPIKey revokes a service-account key by ID
+func (c *APIClient) RevokeAPIKey(ctx context.Context, id string) error {
+	resp, err := c.makeAuthenticatedRequest(ctx, "DELETE", c.BaseURL+"/auth/keys/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// SetToken replaces the current token and its parsed claims, "" clears it
+func (c *APIClient) SetToken(token string) {
+	c.Token = token
+	c.Claims = nil
+	c.TokenType = DetectTokenType(token)
+	if claims, err := c.parseJWTClaims(token); err == nil {
+		c.Claims = claims
+	}
+}
+
+// DetectTokenType tells session JWTs apart from opaque service-account keys
+func DetectTokenType(token string) string {
+	if token == "" {
+		return ""
+	}
+	if _, err := (&APIClient{}).parseJWTClaims(token); err == nil {
+		return TokenTypeJWT
+	}
+	return TokenTypeAPIKey
+}
+
+// recoverToken obtains a new token when the current one is expired or rejected.
+// A token already rotated by another aja process is preferred over refreshing.
+func (c *APIClient) recoverToken(ctx context.Context) error {
+	return c.updateToken(ctx, func(stored string) (string, error) {
+		if stored != "" && stored != c.Token {
+			c.SetToken(stored)
+			if !c.IsTokenExpired() {
+				return stored, nil
+			}
+		}
+		if c.IsAPIKey() {
+			return stored, fmt.Errorf("API key was rejected, it may have been revoked")
+		}
+		return c.refreshToken(ctx)
+	})
+}
+
+// updateToken runs update against the token store, or the in-memory token
+func (c *APIClient) updateToken(ctx context.Context, update func(current string) (string, error)) error {
+	if c.TokenStore == nil {
+		_, err := update(c.Token)
+		return err
+	}
+	return c.TokenStore(ctx, update)
+}
+
 // ensureValidToken checks token validity and refreshes if needed
-func (c *APIClient) ensureValidToken() error {
+func (c *APIClient) ensureValidToken(ctx context.Context) error {
 	if c.Token == "" {
 		return fmt.Errorf("no authentication token")
 	}
 
 	if c.IsTokenExpired() {
-		if err := c.RefreshToken(); err != nil {
-			return fmt.Errorf("token expired and refresh failed: %v", err)
+		if err := c.recoverToken(ctx); err != nil {
+			return fmt.Errorf("token expired and refresh failed: %w", err)
 		}
 	}
 
@@ -176,63 +347,104 @@ func (c *APIClient) ensureValidToken() error {
 
 // API Client methods
 
-func (c *APIClient) makeRequest(method, url string, body interface{}) (*http.Response, error) {
-	var reqBody io.Reader
+func (c *APIClient) makeRequest(ctx context.Context, method, url string, body interface{}, opts ...requestOption) (*http.Response, error) {
+	var jsonData []byte
 
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, err
-	}
+	for attempt := 0; ; attempt++ {
+		// Rebuild the request each attempt so the body can be replayed
+		var reqBody io.Reader
+		if jsonData != nil {
+			reqBody = bytes.NewReader(jsonData)
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-CLI-Version", version.GetVersion())
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, err
+		}
 
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
-	}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-CLI-Version", version.GetVersion())
+		if c.UserAgent != "" {
+			req.Header.Set("User-Agent", c.UserAgent)
+		}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
 
-	if resp.StatusCode >= 400 {
-		var errResp ErrorResponse
-		json.NewDecoder(resp.Body).Decode(&errResp)
-		resp.Body.Close()
+		for _, opt := range opts {
+			opt(req)
+		}
+
+		canRetry := attempt < c.Retry.MaxRetries && isRetryableRequest(req)
 
-		// Handle token expiry specifically
-		if resp.StatusCode == 401 && strings.Contains(strings.ToLower(errResp.Error.Message), "token") {
-			return nil, fmt.Errorf("authentication failed: %s (try running 'aja login')", errResp.Error.Message)
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			if canRetry && isRetryableError(ctx, err) {
+				if err := sleepContext(ctx, c.Retry.backoff(attempt+1)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
 		}
 
-		return nil, fmt.Errorf("API error: %s", errResp.Error.Message)
-	}
+		if resp.StatusCode >= 400 {
+			if canRetry && isRetryableStatus(resp.StatusCode) {
+				delay := c.Retry.backoff(attempt + 1)
+				if d, ok := retryAfter(resp); ok {
+					delay = d
+				}
+				if delay <= maxRetryAfter {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+					if err := sleepContext(ctx, delay); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+			return nil, newError(resp)
+		}
 
-	return resp, nil
+		return resp, nil
+	}
 }
 
 // makeAuthenticatedRequest wraps makeRequest with token validation
-func (c *APIClient) makeAuthenticatedRequest(method, url string, body interface{}) (*http.Response, error) {
-	if err := c.ensureValidToken(); err != nil {
+// A 401 triggers a single token recovery and replay of the request, unless
+// the token was already refreshed while preparing this call.
+func (c *APIClient) makeAuthenticatedRequest(ctx context.Context, method, url string, body interface{}, opts ...requestOption) (*http.Response, error) {
+	initialToken := c.Token
+	if err := c.ensureValidToken(ctx); err != nil {
 		return nil, err
 	}
 
-	return c.makeRequest(method, url, body)
+	resp, err := c.makeRequest(ctx, method, url, body, opts...)
+	if err == nil || !errors.Is(err, ErrUnauthorized) || c.Token != initialToken {
+		return resp, err
+	}
+
+	// The server revoked or rotated the token before its exp claim
+	if recoverErr := c.recoverToken(ctx); recoverErr != nil {
+		return nil, err
+	}
+
+	return c.makeRequest(ctx, method, url, body, opts...)
 }
 
-func (c *APIClient) CheckAuth(sessionCode string) (string, error) {
+func (c *APIClient) CheckAuth(ctx context.Context, sessionCode string) (string, error) {
 	url := fmt.Sprintf("%s/check?ses=%s", c.BaseURL, sessionCode)
 
-	resp, err := c.makeRequest("GET", url, nil)
+	resp, err := c.makeRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return "", err
 	}
@@ -251,19 +463,89 @@ func (c *APIClient) CheckAuth(sessionCode string) (string, error) {
 	return "", fmt.Errorf("authentication pending")
 }
 
-func (c *APIClient) GetCostEstimate(config *config.DeploymentConfig) (*CostResponse, error) {
-	yamlData, err := yaml.Marshal(config)
+// RequestDeviceCode starts a device authorization for headless logins
+func (c *APIClient) RequestDeviceCode(ctx context.Context) (*DeviceCodeResponse, error) {
+	resp, err := c.makeRequest(ctx, "POST", c.BaseURL+"/auth/device/code", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	var deviceResp DeviceCodeResponse
+	err = json.NewDecoder(resp.Body).Decode(&deviceResp)
+	return &deviceResp, err
+}
+
+// WaitForDeviceToken polls until the user approves the device code, honouring
+// the server-directed interval and slow_down responses, until the code expires
+// or ctx is cancelled
+func (c *APIClient) WaitForDeviceToken(ctx context.Context, device *DeviceCodeResponse) (string, error) {
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
 
-	encodedConfig := base64.StdEncoding.EncodeToString(yamlData)
+	var deadline time.Time
+	if device.ExpiresIn > 0 {
+		deadline = time.Now().Add(time.Duration(device.ExpiresIn) * time.Second)
+	}
 
-	body := map[string]string{
-		"deploymentConfig": encodedConfig,
+	body := DeviceTokenRequest{
+		DeviceCode: device.DeviceCode,
 	}
 
-	resp, err := c.makeRequest("POST", c.BaseURL+"/cost", body)
+	for {
+		if err := sleepContext(ctx, interval); err != nil {
+			return "", err
+		}
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return "", ErrDeviceCodeExpired
+		}
+
+		resp, err := c.makeRequest(ctx, "POST", c.BaseURL+"/auth/device/token", body)
+		if err != nil {
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				return "", err
+			}
+
+			switch apiErr.Code {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			case "expired_token":
+				return "", ErrDeviceCodeExpired
+			case "access_denied":
+				return "", ErrAccessDenied
+			}
+			return "", err
+		}
+
+		var tokenResp DeviceTokenResponse
+		err = json.NewDecoder(resp.Body).Decode(&tokenResp)
+		resp.Body.Close()
+		if err != nil {
+			return "", err
+		}
+
+		return tokenResp.Token, nil
+	}
+}
+
+func (c *APIClient) GetCostEstimate(ctx context.Context, config *config.DeploymentConfig) (*CostResponse, error) {
+	yamlData, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	body := CostRequest{
+		DeploymentConfig: base64.StdEncoding.EncodeToString(yamlData),
+	}
+
+	resp, err := c.makeRequest(ctx, "POST", c.BaseURL+"/cost", body)
 	if err != nil {
 		return nil, err
 	}
@@ -274,23 +556,21 @@ func (c *APIClient) GetCostEstimate(config *config.DeploymentConfig) (*CostRespo
 	return &costResp, err
 }
 
-func (c *APIClient) Deploy(config *config.DeploymentConfig, dryRun bool, dockerUsername, dockerPassword, dockerRegistry string) (*DeployResponse, error) {
+func (c *APIClient) Deploy(ctx context.Context, config *config.DeploymentConfig, dryRun bool, dockerUsername, dockerPassword, dockerRegistry string) (*DeployResponse, error) {
 	yamlData, err := yaml.Marshal(config)
 	if err != nil {
 		return nil, err
 	}
 
-	encodedConfig := base64.StdEncoding.EncodeToString(yamlData)
-
-	body := map[string]interface{}{
-		"deploymentConfig": encodedConfig,
-		"username":         dockerUsername,
-		"password":         dockerPassword,
-		"registry":         dockerRegistry,
-		"dryRun":           dryRun,
+	body := DeployRequest{
+		DeploymentConfig: base64.StdEncoding.EncodeToString(yamlData),
+		Username:         dockerUsername,
+		Password:         dockerPassword,
+		Registry:         dockerRegistry,
+		DryRun:           dryRun,
 	}
 
-	resp, err := c.makeAuthenticatedRequest("POST", c.BaseURL+"/deploy", body)
+	resp, err := c.makeAuthenticatedRequest(ctx, "POST", c.BaseURL+"/deploy", body, withIdempotencyKey())
 	if err != nil {
 		return nil, err
 	}
@@ -301,10 +581,10 @@ func (c *APIClient) Deploy(config *config.DeploymentConfig, dryRun bool, dockerU
 	return &deployResp, err
 }
 
-func (c *APIClient) GetStatus() (*StatusResponse, error) {
+func (c *APIClient) GetStatus(ctx context.Context) (*StatusResponse, error) {
 	url := c.BaseURL + "/status"
 
-	resp, err := c.makeAuthenticatedRequest("GET", url, nil)
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -315,102 +595,117 @@ func (c *APIClient) GetStatus() (*StatusResponse, error) {
 	return &statusResp, err
 }
 
-func (c *APIClient) GetLogs(name string, tail int, follow bool) ([]LogEntry, error) {
+func (c *APIClient) GetLogs(ctx context.Context, name string, tail int, follow bool) ([]LogEntry, error) {
 	if follow {
 		return nil, fmt.Errorf("use GetLogsStream for follow mode")
 	}
 
 	url := fmt.Sprintf("%s/logs/%s?tail=%d", c.BaseURL, name, tail)
 
-	resp, err := c.makeAuthenticatedRequest("GET", url, nil)
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var logsResp struct {
-		Logs []LogEntry `json:"logs"`
-	}
+	var logsResp LogsResponse
 	err = json.NewDecoder(resp.Body).Decode(&logsResp)
 	return logsResp.Logs, err
 }
 
-// GetLogsStream streams logs in real-time using Server-Sent Events
-func (c *APIClient) GetLogsStream(name string, tail int, logChan chan<- LogEntry, errorChan chan<- error) {
-	url := fmt.Sprintf("%s/logs/%s/stream?tail=%d", c.BaseURL, name, tail)
+// logStreamHeartbeatTimeout reconnects a log stream that went silent, which
+// usually means a proxy dropped the idle connection without closing it
+const logStreamHeartbeatTimeout = 60 * time.Second
+
+// GetLogsStream streams logs in real-time using Server-Sent Events.
+// Dropped connections are resumed from the last event ID. logChan is closed
+// when the stream ends; a terminal error is then sent on errorChan, which is
+// closed afterwards. Cancelling ctx ends the stream without an error.
+func (c *APIClient) GetLogsStream(ctx context.Context, name string, tail int, logChan chan<- LogEntry, errorChan chan<- error) {
+	defer close(errorChan)
+
+	err := c.StreamLogs(ctx, name, tail, func(entry LogEntry) error {
+		select {
+		case logChan <- entry:
+			return nil
+		case <-ctx.Done():
+			return sse.ErrStop
+		}
+	})
+	close(logChan)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		errorChan <- err
-		return
+	if err != nil && ctx.Err() == nil {
+		select {
+		case errorChan <- err:
+		case <-ctx.Done():
+		}
 	}
+}
 
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("X-CLI-Version", version.GetVersion())
+// StreamLogs calls handle for every log line until the stream ends, ctx is
+// cancelled or handle returns an error. Dropped connections are resumed from
+// the last event ID.
+func (c *APIClient) StreamLogs(ctx context.Context, name string, tail int, handle func(LogEntry) error) error {
+	stream := &sse.Client{
+		HTTPClient:       c.streamClient(),
+		CheckResponse:    func(resp *http.Response) error { return newError(resp) },
+		HeartbeatTimeout: logStreamHeartbeatTimeout,
+		NewRequest: func(ctx context.Context, reconnect bool) (*http.Request, error) {
+			// Lines before the drop were already shown, only resume after them
+			if reconnect {
+				tail = 0
+			}
+			return c.newStreamRequest(ctx, fmt.Sprintf("%s/logs/%s/stream?tail=%d", c.BaseURL, name, tail))
+		},
+	}
+
+	return stream.Subscribe(ctx, func(event sse.Event) error {
+		if event.Data == "[DONE]" {
+			return sse.ErrStop
+		}
+		return handle(parseLogEntry(event.Data))
+	})
+}
 
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
+// newStreamRequest builds an authenticated request for an event stream
+func (c *APIClient) newStreamRequest(ctx context.Context, url string) (*http.Request, error) {
+	// Long streams can outlive the token
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
 	}
 
-	// Create a client without timeout for streaming
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		errorChan <- err
-		return
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		var errResp ErrorResponse
-		json.NewDecoder(resp.Body).Decode(&errResp)
-		errorChan <- fmt.Errorf("API error: %s", errResp.Error.Message)
-		return
+	req.Header.Set("X-CLI-Version", version.GetVersion())
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
 	}
+	return req, nil
+}
 
-	reader := bufio.NewReader(resp.Body)
-
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			if err == io.EOF {
-				// Stream ended normally
-				close(logChan)
-				close(errorChan)
-				return
-			}
-			errorChan <- err
-			return
-		}
-
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+// parseLogEntry decodes a log event. Some servers send the entry as a JSON
+// string, and lines that aren't JSON are shown as plain messages.
+func parseLogEntry(data string) LogEntry {
+	if strings.HasPrefix(data, "\"") {
+		var unquoted string
+		if err := json.Unmarshal([]byte(data), &unquoted); err == nil {
+			data = unquoted
 		}
+	}
 
-		// Parse SSE format: "data: {...}"
-		if strings.HasPrefix(line, "data: ") {
-			data := strings.TrimPrefix(line, "data: ")
-			data = strings.Trim(data, "\"")
-			if data == "[DONE]" {
-				continue
-			}
-
-			var logEntry LogEntry
-			if err := json.Unmarshal([]byte(data), &logEntry); err != nil {
-				errorChan <- fmt.Errorf("failed to parse log entry: %v", err)
-				continue
-			}
-
-			logChan <- logEntry
-		}
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return LogEntry{Message: data}
 	}
+	return entry
 }
 
-func (c *APIClient) ListDeployments() (*StatusResponse, error) {
-	resp, err := c.makeAuthenticatedRequest("GET", c.BaseURL+"/list", nil)
+func (c *APIClient) ListDeployments(ctx context.Context) (*StatusResponse, error) {
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", c.BaseURL+"/list", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -421,13 +716,13 @@ func (c *APIClient) ListDeployments() (*StatusResponse, error) {
 	return &listResp, err
 }
 
-func (c *APIClient) GetDependencies(depType string) (*DependenciesResponse, error) {
+func (c *APIClient) GetDependencies(ctx context.Context, depType string) (*DependenciesResponse, error) {
 	url := c.BaseURL + "/dependencies"
 	if depType != "" {
 		url += "?type=" + depType
 	}
 
-	resp, err := c.makeRequest("GET", url, nil)
+	resp, err := c.makeRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -438,46 +733,40 @@ func (c *APIClient) GetDependencies(depType string) (*DependenciesResponse, erro
 	return &depsResp, err
 }
 
-func (c *APIClient) GetDependencyInstance() (*struct {
-	Instances []DependencyInstanceResponse `json:"dependenciesInstances"`
-}, error) {
+func (c *APIClient) GetDependencyInstance(ctx context.Context) (*DependencyInstancesResponse, error) {
 	url := c.BaseURL + "/depInstance"
 
-	resp, err := c.makeAuthenticatedRequest("GET", url, nil)
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var depInstanceResp struct {
-		Instances []DependencyInstanceResponse `json:"dependenciesInstances"`
-	}
+	var depInstanceResp DependencyInstancesResponse
 	err = json.NewDecoder(resp.Body).Decode(&depInstanceResp)
 	return &depInstanceResp, err
 }
 
-func (c *APIClient) GetEnvVars(deploymentName string) (map[string]string, error) {
+func (c *APIClient) GetEnvVars(ctx context.Context, deploymentName string) (map[string]string, error) {
 	url := c.BaseURL + "/env"
 	if deploymentName != "" {
 		url += "?deploymentName=" + deploymentName
 	}
 
-	resp, err := c.makeAuthenticatedRequest("GET", url, nil)
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var envResp struct {
-		Variables map[string]string `json:"variables"`
-	}
+	var envResp EnvResponse
 	err = json.NewDecoder(resp.Body).Decode(&envResp)
 	return envResp.Variables, err
 }
 
-func (c *APIClient) UpdateEnvVars(vars map[string]string, deploymentName string) error {
-	body := map[string]interface{}{
-		"variables": vars,
+func (c *APIClient) UpdateEnvVars(ctx context.Context, vars map[string]string, deploymentName string) error {
+	body := EnvRequest{
+		Variables: vars,
 	}
 
 	url := c.BaseURL + "/env"
@@ -485,7 +774,7 @@ func (c *APIClient) UpdateEnvVars(vars map[string]string, deploymentName string)
 		url += "?deploymentName=" + deploymentName
 	}
 
-	resp, err := c.makeRequest("PUT", url, body)
+	resp, err := c.makeAuthenticatedRequest(ctx, "PUT", url, body)
 	if err != nil {
 		return err
 	}
@@ -494,12 +783,12 @@ func (c *APIClient) UpdateEnvVars(vars map[string]string, deploymentName string)
 	return nil
 }
 
-func (c *APIClient) Rollback(name string) error {
-	body := map[string]string{
-		"deploymentName": name,
+func (c *APIClient) Rollback(ctx context.Context, name string) error {
+	body := RollbackRequest{
+		DeploymentName: name,
 	}
 
-	resp, err := c.makeRequest("POST", c.BaseURL+"/rollback", body)
+	resp, err := c.makeAuthenticatedRequest(ctx, "POST", c.BaseURL+"/rollback", body, withIdempotencyKey())
 	if err != nil {
 		return err
 	}
@@ -508,10 +797,10 @@ func (c *APIClient) Rollback(name string) error {
 	return nil
 }
 
-func (c *APIClient) Drop(name string) error {
+func (c *APIClient) Drop(ctx context.Context, name string) error {
 	url := fmt.Sprintf("%s/drop/%s", c.BaseURL, name)
 
-	resp, err := c.makeRequest("DELETE", url, nil)
+	resp, err := c.makeAuthenticatedRequest(ctx, "DELETE", url, nil, withIdempotencyKey())
 	if err != nil {
 		return err
 	}
@@ -521,7 +810,7 @@ func (c *APIClient) Drop(name string) error {
 }
 
 // InstallApp retrieves the configuration for a marketplace app
-func (c *APIClient) InstallApp(appName, domain, name string, dryRun bool) (*InstallResponse, error) {
+func (c *APIClient) InstallApp(ctx context.Context, appName, domain, name string, dryRun bool) (*InstallResponse, error) {
 	url := fmt.Sprintf("%s/install?app=%s", c.BaseURL, appName)
 	if domain != "" {
 		url += "&domain=" + domain
@@ -533,7 +822,7 @@ func (c *APIClient) InstallApp(appName, domain, name string, dryRun bool) (*Inst
 		url += "&dryRun=true"
 	}
 
-	resp, err := c.makeRequest("GET", url, nil)
+	resp, err := c.makeRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -545,10 +834,10 @@ func (c *APIClient) InstallApp(appName, domain, name string, dryRun bool) (*Inst
 }
 
 // SearchApps searches for apps in the marketplace
-func (c *APIClient) SearchApps(query string) (*SearchResponse, error) {
+func (c *APIClient) SearchApps(ctx context.Context, query string) (*SearchResponse, error) {
 	url := fmt.Sprintf("%s/search?q=%s", c.BaseURL, query)
 
-	resp, err := c.makeRequest("GET", url, nil)
+	resp, err := c.makeRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -560,7 +849,7 @@ func (c *APIClient) SearchApps(query string) (*SearchResponse, error) {
 }
 
 // ListMarketplaceApps lists all apps in the marketplace with optional filtering and pagination
-func (c *APIClient) ListMarketplaceApps(params map[string]string) (*SearchResponse, error) {
+func (c *APIClient) ListMarketplaceApps(ctx context.Context, params map[string]string) (*SearchResponse, error) {
 	urlStr := c.BaseURL + "/marketplace"
 
 	// Build query parameters
@@ -576,7 +865,7 @@ func (c *APIClient) ListMarketplaceApps(params map[string]string) (*SearchRespon
 		}
 	}
 
-	resp, err := c.makeRequest("GET", urlStr, nil)
+	resp, err := c.makeRequest(ctx, "GET", urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -588,47 +877,47 @@ func (c *APIClient) ListMarketplaceApps(params map[string]string) (*SearchRespon
 }
 
 // Validate validates a deployment configuration via the API
-func (c *APIClient) Validate(config *config.DeploymentConfig) (*ValidateResponse, error) {
+func (c *APIClient) Validate(ctx context.Context, config *config.DeploymentConfig) (*ValidateResponse, error) {
 	yamlData, err := yaml.Marshal(config)
 	if err != nil {
 		return nil, err
 	}
 
-	encodedConfig := base64.StdEncoding.EncodeToString(yamlData)
-
-	body := map[string]string{
-		"deploymentConfig": encodedConfig,
+	body := ValidateRequest{
+		DeploymentConfig: base64.StdEncoding.EncodeToString(yamlData),
 	}
 
-	resp, err := c.makeRequest("POST", c.BaseURL+"/validate", body)
+	resp, err := c.makeRequest(ctx, "POST", c.BaseURL+"/validate", body)
 	if err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			message := apiErr.Message
+			var validateErrResp ValidateErrorResponse
+			if json.Unmarshal(apiErr.body, &validateErrResp) == nil && validateErrResp.Error.Message != "" {
+				message = validateErrResp.Error.Message
+			}
+			apiErr.Message = message
+			return &ValidateResponse{
+				Valid:   false,
+				Message: message,
+			}, apiErr
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == 400 {
-		var validateErrResp ValidateErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&validateErrResp); err != nil {
-			return nil, fmt.Errorf("failed to parse validation error response: %v", err)
-		}
-		return &ValidateResponse{
-			Valid:   false,
-			Message: validateErrResp.Error.Error.Message,
-		}, fmt.Errorf("validation failed: %s", validateErrResp.Error.Error.Message)
-	}
-
 	var validateResp ValidateResponse
 	err = json.NewDecoder(resp.Body).Decode(&validateResp)
 	return &validateResp, err
 }
 
 // Gen generates aja configuration based on a prompt
-func (c *APIClient) Gen(prompt string) (*GenResponse, error) {
+func (c *APIClient) Gen(ctx context.Context, prompt string) (*GenResponse, error) {
 	body := GenRequest{
 		Prompt: prompt,
 	}
 
-	resp, err := c.makeAuthenticatedRequest("POST", c.BaseURL+"/gen", body)
+	resp, err := c.makeAuthenticatedRequest(ctx, "POST", c.BaseURL+"/gen", body)
 	if err != nil {
 		return nil, err
 	}
@@ -639,12 +928,8 @@ func (c *APIClient) Gen(prompt string) (*GenResponse, error) {
 	return &genResp, err
 }
 
-func (c *APIClient) Describe(deploymentName string) (*DescribeResponse, error) {
-	if err := c.ensureValidToken(); err != nil {
-		return nil, err
-	}
-
-	resp, err := c.makeAuthenticatedRequest("GET", c.BaseURL+"/describe/"+url.QueryEscape(deploymentName), nil)
+func (c *APIClient) Describe(ctx context.Context, deploymentName string) (*DescribeResponse, error) {
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", c.BaseURL+"/describe/"+url.QueryEscape(deploymentName), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -662,6 +947,7 @@ func (c *APIClient) Describe(deploymentName string) (*DescribeResponse, error) {
 // It reads the config from deployaja.yaml or from the file specified by filePath (if not empty).
 // The config is base64-encoded and sent as part of the request.
 func (c *APIClient) PublishApp(
+	ctx context.Context,
 	name, description, category, author, version, repository, image string,
 	tags []string,
 	configFilePath string,
@@ -678,52 +964,37 @@ func (c *APIClient) PublishApp(
 		return nil, fmt.Errorf("failed to read config file '%s': %v", filePath, err)
 	}
 
-	// Base64 encode config
-	configBase64 := base64.StdEncoding.EncodeToString(configData)
-
 	// Prepare request body
-	reqBody := map[string]interface{}{
-		"name":        name,
-		"description": description,
-		"category":    category,
-		"author":      author,
-		"version":     version,
-		"repository":  repository,
-		"image":       image,
-		"tags":        tags,
-		"config":      configBase64,
-		"downloads":   0,
-		"rating":      0,
-		"isActive":    true,
-	}
-
-	resp, err := c.makeAuthenticatedRequest("POST", c.BaseURL+"/publish", reqBody)
+	reqBody := PublishRequest{
+		Name:        name,
+		Description: description,
+		Category:    category,
+		Author:      author,
+		Version:     version,
+		Repository:  repository,
+		Image:       image,
+		Tags:        tags,
+		Config:      base64.StdEncoding.EncodeToString(configData),
+		IsActive:    true,
+	}
+
+	resp, err := c.makeAuthenticatedRequest(ctx, "POST", c.BaseURL+"/publish", reqBody, withIdempotencyKey())
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		var errResp ErrorResponse
-		json.NewDecoder(resp.Body).Decode(&errResp)
-		return nil, fmt.Errorf("%s", errResp.Error.Message)
-	}
-
 	var appResp AppResponse
 	err = json.NewDecoder(resp.Body).Decode(&appResp)
 	return &appResp, err
 }
 
-func (c *APIClient) Restart(deploymentName string) (*RestartResponse, error) {
-	if err := c.ensureValidToken(); err != nil {
-		return nil, err
-	}
-
-	requestBody := map[string]string{
-		"deploymentName": deploymentName,
+func (c *APIClient) Restart(ctx context.Context, deploymentName string) (*RestartResponse, error) {
+	requestBody := RestartRequest{
+		DeploymentName: deploymentName,
 	}
 
-	resp, err := c.makeAuthenticatedRequest("POST", c.BaseURL+"/restart", requestBody)
+	resp, err := c.makeAuthenticatedRequest(ctx, "POST", c.BaseURL+"/restart", requestBody, withIdempotencyKey())
 	if err != nil {
 		return nil, err
 	}
@@ -738,64 +1009,80 @@ func (c *APIClient) Restart(deploymentName string) (*RestartResponse, error) {
 }
 
 // GetDeploymentStatus gets the status of a specific deployment by name
-func (c *APIClient) GetDeploymentStatus(deploymentName string) (*DeploymentStatus, error) {
-	statusResp, err := c.GetStatus()
-	if err != nil {
-		return nil, err
-	}
-
-	for _, deployment := range statusResp.Deployments {
-		if deployment.Name == deploymentName {
-			return &deployment, nil
-		}
-	}
-
-	return nil, fmt.Errorf("deployment '%s' not found", deploymentName)
+func (c *APIClient) GetDeploymentStatus(ctx context.Context, deploymentName string) (*DeploymentStatus, error) {
+	return c.GetDeployment(ctx, deploymentName)
 }
 
-// PollDeploymentStatus polls for deployment status until it reaches a final state
-func (c *APIClient) PollDeploymentStatus(deploymentName string, onStatusUpdate func(status string)) (*DeploymentStatus, error) {
-	const maxPollingDuration = 10 * time.Minute
-	const pollInterval = 10 * time.Second
+// PollDeploymentStatus watches a deployment until it reaches one of the
+// final states of opts, and returns it whether the rollout succeeded or not.
+// With opts.Previous set, the wait starts once the new rollout shows.
+// onUpdate is called whenever the deployment changes, an error it returns
+// ends the wait. Running out of opts.Timeout is reported as ErrRolloutTimeout.
+func (c *APIClient) PollDeploymentStatus(ctx context.Context, deploymentName string, opts WaitOptions, onUpdate func(*DeploymentStatus) error) (*DeploymentStatus, error) {
+	opts = opts.withDefaults()
+
+	finalStates := map[string]bool{}
+	for _, state := range opts.FinalStates {
+		finalStates[state] = true
+	}
 
-	finalStates := map[string]bool{
-		"success":   true,
-		"running":   true,
-		"failed":    true,
-		"error":     true,
-		"cancelled": true,
-		"timeout":   true,
+	policy := c.Watch
+	if opts.PollInterval > 0 {
+		policy = WatchPolicy{MinInterval: opts.PollInterval, MaxInterval: opts.PollInterval}
 	}
 
+	watchCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
 	startTime := time.Now()
+	started := false
 
 	for {
-		// Check if we've exceeded the maximum polling duration
-		if time.Since(startTime) > maxPollingDuration {
-			return nil, fmt.Errorf("polling timeout: deployment did not complete within %v", maxPollingDuration)
-		}
+		var final *DeploymentStatus
+		err := c.watchDeployment(watchCtx, deploymentName, policy, func(deployment *DeploymentStatus) error {
+			if !started && !opts.rolloutStarted(deployment, finalStates) {
+				return nil
+			}
+			started = true
 
-		deployment, err := c.GetDeploymentStatus(deploymentName)
-		if err != nil {
-			// If deployment not found initially, wait and try again
-			if strings.Contains(err.Error(), "not found") && time.Since(startTime) < 30*time.Second {
-				time.Sleep(pollInterval)
-				continue
+			if onUpdate != nil {
+				if err := onUpdate(deployment); err != nil {
+					return err
+				}
+			}
+			if finalStates[deployment.Status] {
+				final = deployment
+				return ErrStopWatch
 			}
+			return nil
+		})
+
+		switch {
+		case final != nil:
+			return final, nil
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case watchCtx.Err() != nil:
+			return nil, fmt.Errorf("%w: deployment did not complete within %v", ErrRolloutTimeout, opts.Timeout)
+		case err != nil && !(errors.Is(err, ErrNotFound) && time.Since(startTime) < opts.NotFoundGracePeriod):
 			return nil, err
 		}
 
-		// Call the status update callback
-		if onStatusUpdate != nil {
-			onStatusUpdate(deployment.Status)
-		}
+		// A new deployment may not be listed yet, or the stream ended
+		// before a final state: watch again
+		sleepContext(watchCtx, policy.next(0, true))
+	}
+}
 
-		// Check if we've reached a final state
-		if finalStates[deployment.Status] {
-			return deployment, nil
-		}
+// sleepContext waits for the given duration or until the context is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-		// Wait before polling again
-		time.Sleep(pollInterval)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	redacted = "[REDACTED]"
+
+	// maxDebugBody caps how much of a body is written to the trace
+	maxDebugBody = 64 * 1024
+)
+
+// sensitiveHeaders are never written to the trace
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveKeys are body fields whose values are never written to the trace
+var sensitiveKeys = map[string]bool{
+	"password":   true,
+	"passphrase": true,
+	"secret":     true,
+	"token":      true,
+	"auth":       true,
+	"key":        true,
+	"apikey":     true,
+	// Device login codes and sessions can be exchanged for a token
+	"devicecode":  true,
+	"device_code": true,
+	"ses":         true,
+	"session":     true,
+	"sessioncode": true,
+}
+
+// envKeys hold environment variables, whose values may contain credentials
+var envKeys = map[string]bool{
+	"variables": true,
+	"envmap":    true,
+}
+
+// encodedConfigKeys carry a base64-encoded deployaja.yaml
+var encodedConfigKeys = map[string]bool{
+	"deploymentconfig": true,
+	"config":           true,
+}
+
+// EnableDebug writes a redacted trace of every request and response to w
+func (c *APIClient) EnableDebug(w io.Writer) {
+	next := c.HTTPClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	c.HTTPClient = &http.Client{
+		Transport: &debugTransport{next: next, out: w},
+		Timeout:   c.HTTPClient.Timeout,
+	}
+}
+
+// debugTransport logs requests and responses passing through the next transport
+type debugTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+	mu   sync.Mutex
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "--> %s %s\n", req.Method, redactURL(req.URL))
+	writeHeaders(&buf, req.Header)
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			writeBody(&buf, req.Header.Get("Content-Type"), data)
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		fmt.Fprintf(&buf, "<-- %s %s failed after %s: %v\n\n", req.Method, redactURL(req.URL), latency, err)
+		t.write(buf.Bytes())
+		return nil, err
+	}
+
+	fmt.Fprintf(&buf, "<-- %s %s (%s)\n", resp.Status, redactURL(req.URL), latency)
+	writeHeaders(&buf, resp.Header)
+
+	// Event streams are consumed incrementally and must not be buffered here
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/event-stream") {
+		buf.WriteString("[event stream]\n\n")
+		t.write(buf.Bytes())
+		return resp, nil
+	}
+
+	data, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// Replay the body to the caller, including a failure to read it
+	var body io.Reader = bytes.NewReader(data)
+	if readErr != nil {
+		fmt.Fprintf(&buf, "[failed to read body: %v]\n", readErr)
+		body = io.MultiReader(body, &errorReader{err: readErr})
+	}
+	resp.Body = io.NopCloser(body)
+
+	writeBody(&buf, contentType, data)
+	buf.WriteString("\n")
+
+	t.write(buf.Bytes())
+	return resp, nil
+}
+
+// redactURL hides the password and the sensitive query parameters of u,
+// like the session code of the device login poll
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for name := range query {
+		if isSensitiveKey(strings.ToLower(name)) {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.Redacted()
+	}
+
+	copied := *u
+	// Keep the marker readable instead of percent-encoded
+	copied.RawQuery = strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+	return copied.Redacted()
+}
+
+// errorReader fails every read with err
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+// write emits a whole exchange at once so concurrent requests don't interleave
+func (t *debugTransport) write(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out.Write(p)
+}
+
+func writeHeaders(buf *bytes.Buffer, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		fmt.Fprintf(buf, "    %s: %s\n", name, value)
+	}
+}
+
+// writeBody pretty-prints a redacted JSON body, decoding embedded deployment configs
+func writeBody(buf *bytes.Buffer, contentType string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	var payload interface{}
+	if !strings.Contains(contentType, "json") || json.Unmarshal(data, &payload) != nil {
+		if len(data) > maxDebugBody {
+			fmt.Fprintf(buf, "%s\n[%d more bytes]\n", data[:maxDebugBody], len(data)-maxDebugBody)
+			return
+		}
+		fmt.Fprintf(buf, "%s\n", data)
+		return
+	}
+
+	configs := map[string]string{}
+	payload = redactValue("", payload, configs)
+
+	pretty, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return
+	}
+	if len(pretty) > maxDebugBody {
+		fmt.Fprintf(buf, "%s\n[%d more bytes]\n", pretty[:maxDebugBody], len(pretty)-maxDebugBody)
+	} else {
+		fmt.Fprintf(buf, "%s\n", pretty)
+	}
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(buf, "--- %s (decoded) ---\n%s", name, configs[name])
+	}
+}
+
+// redactValue replaces secrets in a decoded JSON or YAML value. Base64
+// deployment configs are decoded, redacted and collected into configs.
+func redactValue(key string, value interface{}, configs map[string]string) interface{} {
+	lower := strings.ToLower(key)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = redactValue(k, item, configs)
+		}
+		if envKeys[lower] {
+			for k := range v {
+				v[k] = redacted
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue("", item, configs)
+		}
+		if lower == "env" {
+			for _, item := range v {
+				if entry, ok := item.(map[string]interface{}); ok {
+					if _, ok := entry["value"]; ok {
+						entry["value"] = redacted
+					}
+				}
+			}
+		}
+		return v
+	case string:
+		if v == "" {
+			return v
+		}
+		if isSensitiveKey(lower) {
+			return redacted
+		}
+		if encodedConfigKeys[lower] {
+			if decoded, ok := decodeConfig(v, configs); ok {
+				configs[key] = decoded
+				return "[base64, decoded below]"
+			}
+		}
+		return v
+	case nil:
+		return v
+	default:
+		if isSensitiveKey(lower) {
+			return redacted
+		}
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	return sensitiveKeys[key] ||
+		strings.HasSuffix(key, "password") ||
+		strings.HasSuffix(key, "secret") ||
+		strings.HasSuffix(key, "token")
+}
+
+// decodeConfig decodes a base64 deployaja.yaml and returns it redacted
+func decodeConfig(encoded string, configs map[string]string) (string, bool) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil || config == nil {
+		return "", false
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(redactValue("", config, configs)); err != nil {
+		return "", false
+	}
+	return out.String(), true
+}
@@ -0,0 +1,440 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/sse"
+)
+
+// ErrStopWatch can be returned by a WatchDeployment handler to end the watch
+// without error
+var ErrStopWatch = errors.New("stop watching")
+
+// deploymentStreamHeartbeatTimeout reconnects a watch that went silent, the
+// platform sends a comment at least every 30s
+const deploymentStreamHeartbeatTimeout = 60 * time.Second
+
+// WatchPolicy controls how WatchDeployment polls servers that can't stream
+// status changes. Polling starts at MinInterval and backs off to MaxInterval
+// while the deployment doesn't change.
+type WatchPolicy struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// DefaultWatchPolicy returns the policy used when none is configured
+func DefaultWatchPolicy() WatchPolicy {
+	return WatchPolicy{
+		MinInterval: 2 * time.Second,
+		MaxInterval: 30 * time.Second,
+	}
+}
+
+// next returns the delay before the next poll
+func (p WatchPolicy) next(current time.Duration, changed bool) time.Duration {
+	defaults := DefaultWatchPolicy()
+	if p.MinInterval <= 0 {
+		p.MinInterval = defaults.MinInterval
+	}
+	if p.MaxInterval < p.MinInterval {
+		p.MaxInterval = max(defaults.MaxInterval, p.MinInterval)
+	}
+
+	if changed || current < p.MinInterval {
+		return p.MinInterval
+	}
+	return min(current*2, p.MaxInterval)
+}
+
+// Deployment states PollDeploymentStatus stops at unless told otherwise
+var (
+	successStates = []string{"success", "running"}
+	failureStates = []string{"failed", "error", "cancelled"}
+	timeoutStates = []string{"timeout"}
+)
+
+// DefaultFinalStates returns the states PollDeploymentStatus stops at by default
+func DefaultFinalStates() []string {
+	var states []string
+	states = append(states, successStates...)
+	states = append(states, failureStates...)
+	return append(states, timeoutStates...)
+}
+
+// WaitOptions control how PollDeploymentStatus waits for a rollout
+type WaitOptions struct {
+	// Timeout bounds the whole wait
+	Timeout time.Duration
+	// PollInterval is the fixed pause between polls of servers that can't
+	// stream status changes. Zero backs off according to the client's Watch
+	// policy.
+	PollInterval time.Duration
+	// FinalStates end the wait, DefaultFinalStates when empty
+	FinalStates []string
+	// NotFoundGracePeriod tolerates a new deployment not being listed yet
+	NotFoundGracePeriod time.Duration
+	// Previous is the state of the deployment before the rollout was
+	// started, for rollouts of a deployment that may already be in a final
+	// state, like restarts. Until the deployment leaves its final state, is
+	// deployed again or has its pods replaced, it is still reporting the
+	// previous rollout: those updates are skipped and don't end the wait.
+	Previous *DeploymentStatus
+}
+
+// rolloutStarted reports whether deployment shows the rollout waited for
+// rather than the one before it. A rollout the platform completes between
+// two updates without changing LastDeployed or replacing the pods shows no
+// trace of itself, and the wait runs into its timeout.
+func (o WaitOptions) rolloutStarted(deployment *DeploymentStatus, finalStates map[string]bool) bool {
+	if o.Previous == nil || !finalStates[deployment.Status] || deployment.LastDeployed != o.Previous.LastDeployed {
+		return true
+	}
+	return podsReplaced(o.Previous, deployment)
+}
+
+// podsReplaced reports whether none of the pods of previous is left in
+// current, as after a restart or a new revision
+func podsReplaced(previous, current *DeploymentStatus) bool {
+	if len(previous.Pods) == 0 || len(current.Pods) == 0 {
+		return false
+	}
+
+	names := map[string]bool{}
+	for _, pod := range previous.Pods {
+		names[pod.Name] = true
+	}
+	for _, pod := range current.Pods {
+		if names[pod.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultWaitOptions returns the options used when none are configured
+func DefaultWaitOptions() WaitOptions {
+	return WaitOptions{
+		Timeout:             10 * time.Minute,
+		FinalStates:         DefaultFinalStates(),
+		NotFoundGracePeriod: 30 * time.Second,
+	}
+}
+
+// withDefaults fills the unset options from DefaultWaitOptions
+func (o WaitOptions) withDefaults() WaitOptions {
+	defaults := DefaultWaitOptions()
+	if o.Timeout <= 0 {
+		o.Timeout = defaults.Timeout
+	}
+	if len(o.FinalStates) == 0 {
+		o.FinalStates = defaults.FinalStates
+	}
+	if o.NotFoundGracePeriod <= 0 {
+		o.NotFoundGracePeriod = defaults.NotFoundGracePeriod
+	}
+	return o
+}
+
+// RolloutOutcome classifies the final state of a rollout: nil for success,
+// otherwise an error matching ErrRolloutFailed, ErrRolloutTimeout or, for
+// states that are neither, ErrRolloutUnknown
+func RolloutOutcome(deployment *DeploymentStatus) error {
+	switch {
+	case slices.Contains(successStates, deployment.Status):
+		return nil
+	case slices.Contains(failureStates, deployment.Status):
+		return fmt.Errorf("%w with status '%s'", ErrRolloutFailed, deployment.Status)
+	case slices.Contains(timeoutStates, deployment.Status):
+		return fmt.Errorf("%w on the platform", ErrRolloutTimeout)
+	default:
+		return fmt.Errorf("%w: deployment stopped in status '%s'", ErrRolloutUnknown, deployment.Status)
+	}
+}
+
+// CrashLoopingPod returns the first pod whose containers keep crashing and
+// being restarted, nil when there is none. A crash loop doesn't heal on its
+// own, so waiting for the rollout to time out is pointless.
+func CrashLoopingPod(deployment *DeploymentStatus) *Pod {
+	for i, pod := range deployment.Pods {
+		if pod.Status == "CRASH_LOOP" || pod.Reason == "CrashLoopBackOff" {
+			return &deployment.Pods[i]
+		}
+		for _, container := range pod.ContainerStatuses {
+			if container.Reason == "CrashLoopBackOff" {
+				return &deployment.Pods[i]
+			}
+		}
+	}
+	return nil
+}
+
+// DeploymentCache remembers the last version of each deployment read with
+// GetDeployment, so unchanged deployments are revalidated with If-None-Match
+// instead of downloaded again. It is safe for concurrent use.
+type DeploymentCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedDeployment
+	// listOnly is set once the server turned out not to serve single
+	// deployments, GetDeployment then filters /status
+	listOnly bool
+}
+
+type cachedDeployment struct {
+	etag   string
+	status DeploymentStatus
+}
+
+// NewDeploymentCache returns an empty cache
+func NewDeploymentCache() *DeploymentCache {
+	return &DeploymentCache{entries: map[string]cachedDeployment{}}
+}
+
+func (dc *DeploymentCache) get(name string) (cachedDeployment, bool) {
+	if dc == nil {
+		return cachedDeployment{}, false
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	entry, exists := dc.entries[name]
+	return entry, exists
+}
+
+func (dc *DeploymentCache) put(name, etag string, status DeploymentStatus) {
+	if dc == nil {
+		return
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if etag == "" {
+		delete(dc.entries, name)
+		return
+	}
+	dc.entries[name] = cachedDeployment{etag: etag, status: status}
+}
+
+func (dc *DeploymentCache) forget(name string) {
+	dc.put(name, "", DeploymentStatus{})
+}
+
+func (dc *DeploymentCache) isListOnly() bool {
+	if dc == nil {
+		return false
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return dc.listOnly
+}
+
+func (dc *DeploymentCache) setListOnly() {
+	if dc == nil {
+		return
+	}
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.listOnly = true
+}
+
+// GetDeployment returns a single deployment from /deployments/{name}. With a
+// Cache, an unchanged deployment costs a 304 Not Modified round trip. Servers
+// without the endpoint are asked for the /status list instead.
+func (c *APIClient) GetDeployment(ctx context.Context, name string) (*DeploymentStatus, error) {
+	if c.Cache.isListOnly() {
+		return c.findDeployment(ctx, name)
+	}
+
+	var opts []requestOption
+	cached, isCached := c.Cache.get(name)
+	if isCached {
+		opts = append(opts, func(req *http.Request) {
+			req.Header.Set("If-None-Match", cached.etag)
+		})
+	}
+
+	resp, err := c.makeAuthenticatedRequest(ctx, "GET", c.BaseURL+"/deployments/"+url.PathEscape(name), nil, opts...)
+	if err != nil {
+		if isUnsupported(err) {
+			c.Cache.setListOnly()
+			return c.findDeployment(ctx, name)
+		}
+		if errors.Is(err, ErrNotFound) {
+			c.Cache.forget(name)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && isCached {
+		status := cached.status
+		return &status, nil
+	}
+
+	var status DeploymentStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	c.Cache.put(name, resp.Header.Get("ETag"), status)
+	return &status, nil
+}
+
+// findDeployment filters the /status list, for servers without /deployments/{name}
+func (c *APIClient) findDeployment(ctx context.Context, name string) (*DeploymentStatus, error) {
+	statusResp, err := c.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deployment := range statusResp.Deployments {
+		if deployment.Name == name {
+			return &deployment, nil
+		}
+	}
+
+	return nil, fmt.Errorf("deployment '%s': %w", name, ErrNotFound)
+}
+
+// isUnsupported reports responses of servers that predate an endpoint. A
+// missing deployment is reported with the DEPLOYMENT_NOT_FOUND code instead.
+func isUnsupported(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		return apiErr.Code != "DEPLOYMENT_NOT_FOUND"
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// WatchDeployment calls handle with the current state of a deployment and
+// then with every change, until ctx is cancelled, handle returns an error or
+// the deployment is dropped, which is reported as ErrNotFound. Returning
+// ErrStopWatch from handle ends the watch without error.
+//
+// Changes are streamed from /deployments/{name}/watch over SSE. Servers that
+// can't stream are polled according to the client's Watch policy.
+func (c *APIClient) WatchDeployment(ctx context.Context, name string, handle func(*DeploymentStatus) error) error {
+	return c.watchDeployment(ctx, name, c.Watch, handle)
+}
+
+// watchDeployment is WatchDeployment with the given polling policy
+func (c *APIClient) watchDeployment(ctx context.Context, name string, policy WatchPolicy, handle func(*DeploymentStatus) error) error {
+	var last string
+	onChange := func(status *DeploymentStatus) (bool, error) {
+		fingerprint := statusFingerprint(status)
+		if fingerprint == last {
+			return false, nil
+		}
+		last = fingerprint
+		return true, handle(status)
+	}
+
+	err := c.streamDeployment(ctx, name, onChange)
+	if errors.Is(err, errStreamUnavailable) {
+		err = c.pollDeployment(ctx, name, policy, onChange)
+	}
+	if errors.Is(err, ErrStopWatch) {
+		return nil
+	}
+	return err
+}
+
+// errStreamUnavailable is returned by streamDeployment when the server can't
+// stream status changes
+var errStreamUnavailable = errors.New("deployment watch stream is unavailable")
+
+func (c *APIClient) streamDeployment(ctx context.Context, name string, onChange func(*DeploymentStatus) (bool, error)) error {
+	stream := &sse.Client{
+		HTTPClient: c.streamClient(),
+		CheckResponse: func(resp *http.Response) error {
+			err := newError(resp)
+			if isUnsupported(err) {
+				return errStreamUnavailable
+			}
+			return err
+		},
+		HeartbeatTimeout: deploymentStreamHeartbeatTimeout,
+		NewRequest: func(ctx context.Context, reconnect bool) (*http.Request, error) {
+			return c.newStreamRequest(ctx, c.BaseURL+"/deployments/"+url.PathEscape(name)+"/watch")
+		},
+	}
+
+	var handleErr error
+	err := stream.Subscribe(ctx, func(event sse.Event) error {
+		switch {
+		case event.Data == "[DONE]":
+			return sse.ErrStop
+		case event.Type == "deleted":
+			handleErr = fmt.Errorf("deployment '%s': %w", name, ErrNotFound)
+			return sse.ErrStop
+		}
+
+		var status DeploymentStatus
+		if err := json.Unmarshal([]byte(event.Data), &status); err != nil {
+			return fmt.Errorf("invalid deployment event: %v", err)
+		}
+		if _, err := onChange(&status); err != nil {
+			handleErr = err
+			return sse.ErrStop
+		}
+		return nil
+	})
+
+	if errors.Is(err, sse.ErrNotEventStream) {
+		return errStreamUnavailable
+	}
+	if err != nil {
+		return err
+	}
+	return handleErr
+}
+
+// pollDeployment polls GetDeployment, quickly while the deployment changes
+// and backing off while it doesn't
+func (c *APIClient) pollDeployment(ctx context.Context, name string, policy WatchPolicy, onChange func(*DeploymentStatus) (bool, error)) error {
+	var interval time.Duration
+	for {
+		status, err := c.GetDeployment(ctx, name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		changed, err := onChange(status)
+		if err != nil {
+			return err
+		}
+
+		interval = policy.next(interval, changed)
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil
+		}
+	}
+}
+
+// statusFingerprint identifies a state of a deployment, ignoring the pod
+// ages that change on every read
+func statusFingerprint(status *DeploymentStatus) string {
+	normalized := *status
+	normalized.Pods = make([]Pod, len(status.Pods))
+	for i, pod := range status.Pods {
+		pod.Age = ""
+		normalized.Pods[i] = pod
+	}
+
+	data, _ := json.Marshal(normalized)
+	return string(data)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pollServer serves the states of the deployment shop in turn, repeating
+// the last one, to clients that poll
+func pollServer(t *testing.T, states ...DeploymentStatus) *APIClient {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/deployments/shop" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		state := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+		mu.Unlock()
+		json.NewEncoder(w).Encode(state)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewApiClientWithURL(server.URL, "token")
+	client.Retry = RetryPolicy{}
+	return client
+}
+
+func TestPollDeploymentStatusSkipsThePreviousRollout(t *testing.T) {
+	previous := DeploymentStatus{Name: "shop", Status: "running", LastDeployed: "2026-10-01T10:00:00Z", Pods: []Pod{{Name: "shop-r1-0"}}}
+	tests := map[string]DeploymentStatus{
+		"status changed": {Name: "shop", Status: "deploying", LastDeployed: previous.LastDeployed, Pods: previous.Pods},
+		"deployed again": {Name: "shop", Status: "running", LastDeployed: "2026-10-01T11:00:00Z", Pods: previous.Pods},
+		"pods replaced":  {Name: "shop", Status: "running", LastDeployed: previous.LastDeployed, Pods: []Pod{{Name: "shop-r2-0"}}},
+	}
+	for name, next := range tests {
+		t.Run(name, func(t *testing.T) {
+			done := DeploymentStatus{Name: "shop", Status: "running", LastDeployed: "2026-10-01T11:00:00Z", Pods: []Pod{{Name: "shop-r2-0"}}}
+			client := pollServer(t, previous, previous, next, done)
+
+			var updates []DeploymentStatus
+			final, err := client.PollDeploymentStatus(context.Background(), "shop", WaitOptions{
+				Timeout:      2 * time.Second,
+				PollInterval: time.Millisecond,
+				Previous:     &previous,
+			}, func(deployment *DeploymentStatus) error {
+				updates = append(updates, *deployment)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("PollDeploymentStatus: %v", err)
+			}
+			if statusFingerprint(final) == statusFingerprint(&previous) {
+				t.Errorf("the wait ended on the previous rollout")
+			}
+			if len(updates) == 0 || statusFingerprint(&updates[0]) != statusFingerprint(&next) {
+				t.Errorf("updates = %+v, want the previous rollout skipped", updates)
+			}
+		})
+	}
+}
+
+func TestPollDeploymentStatusTimesOutWithoutTraceOfTheRollout(t *testing.T) {
+	// A rollout finished between two polls, with neither LastDeployed nor
+	// the pods changed, can't be told apart from the previous one
+	previous := DeploymentStatus{Name: "shop", Status: "running", LastDeployed: "2026-10-01T10:00:00Z", Pods: []Pod{{Name: "shop-r1-0"}}}
+	client := pollServer(t, previous)
+
+	_, err := client.PollDeploymentStatus(context.Background(), "shop", WaitOptions{
+		Timeout:      50 * time.Millisecond,
+		PollInterval: time.Millisecond,
+		Previous:     &previous,
+	}, nil)
+	if !errors.Is(err, ErrRolloutTimeout) {
+		t.Fatalf("err = %v, want ErrRolloutTimeout", err)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for the API failure classes callers usually branch on.
+// Match them with errors.Is against any error returned by APIClient.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+
+	// Device authorization outcomes returned by WaitForDeviceToken
+	ErrDeviceCodeExpired = errors.New("device code expired, run 'aja login' again")
+	ErrAccessDenied      = errors.New("login request was denied")
+
+	// Rollout outcomes, see RolloutOutcome and PollDeploymentStatus
+	ErrRolloutFailed  = errors.New("rollout failed")
+	ErrRolloutTimeout = errors.New("rollout timed out")
+	ErrRolloutUnknown = errors.New("rollout outcome unknown")
+)
+
+// Error is returned for every non-2xx API response
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    interface{}
+	RequestID  string
+
+	// body holds the raw response body for endpoints with custom error shapes
+	body []byte
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	if e.StatusCode == http.StatusUnauthorized {
+		return fmt.Sprintf("authentication failed: %s (try running 'aja login')", msg)
+	}
+
+	if e.Code != "" {
+		return fmt.Sprintf("API error: %s (%s)", msg, e.Code)
+	}
+	return fmt.Sprintf("API error: %s", msg)
+}
+
+// Is reports whether the error belongs to the class of the given sentinel
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// newError builds an Error from a failed response and closes its body.
+// Bodies that are not a JSON ErrorResponse are used verbatim as the message.
+func newError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	apiErr.body = data
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(data, &errResp); err == nil && errResp.Error.Message != "" {
+		apiErr.Code = errResp.Error.Code
+		apiErr.Message = errResp.Error.Message
+		apiErr.Details = errResp.Error.Details
+		return apiErr
+	}
+
+	// HTML error pages from proxies are noise, fall back to the status text
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		apiErr.Message = strings.TrimSpace(string(data))
+		if len(apiErr.Message) > 200 {
+			apiErr.Message = apiErr.Message[:200] + "..."
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// DeployAjaAPI is the set of platform operations used by the CLI commands.
+// *APIClient implements it; tests and embedding programs can provide fakes.
+type DeployAjaAPI interface {
+	// Token state
+	HasToken() bool
+	SetToken(token string)
+	IsAPIKey() bool
+	IsTokenExpired() bool
+	GetTokenInfo() map[string]interface{}
+	GetBaseURL() string
+	GetLoginURL() string
+
+	// Authentication
+	CheckAuth(ctx context.Context, sessionCode string) (string, error)
+	RequestDeviceCode(ctx context.Context) (*DeviceCodeResponse, error)
+	WaitForDeviceToken(ctx context.Context, device *DeviceCodeResponse) (string, error)
+	RefreshToken(ctx context.Context) error
+	RevokeToken(ctx context.Context) error
+	CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) (*APIKeysResponse, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+
+	// Deployments
+	GetCostEstimate(ctx context.Context, config *config.DeploymentConfig) (*CostResponse, error)
+	Validate(ctx context.Context, config *config.DeploymentConfig) (*ValidateResponse, error)
+	Deploy(ctx context.Context, config *config.DeploymentConfig, dryRun bool, dockerUsername, dockerPassword, dockerRegistry string) (*DeployResponse, error)
+	GetStatus(ctx context.Context) (*StatusResponse, error)
+	ListDeployments(ctx context.Context) (*StatusResponse, error)
+	GetDeployment(ctx context.Context, name string) (*DeploymentStatus, error)
+	WatchDeployment(ctx context.Context, name string, handle func(*DeploymentStatus) error) error
+	GetDeploymentStatus(ctx context.Context, deploymentName string) (*DeploymentStatus, error)
+	PollDeploymentStatus(ctx context.Context, deploymentName string, opts WaitOptions, onUpdate func(*DeploymentStatus) error) (*DeploymentStatus, error)
+	Describe(ctx context.Context, deploymentName string) (*DescribeResponse, error)
+	Restart(ctx context.Context, deploymentName string) (*RestartResponse, error)
+	Rollback(ctx context.Context, name string) error
+	Drop(ctx context.Context, name string) error
+	GetLogs(ctx context.Context, name string, tail int, follow bool) ([]LogEntry, error)
+	GetLogsStream(ctx context.Context, name string, tail int, logChan chan<- LogEntry, errorChan chan<- error)
+	GetEnvVars(ctx context.Context, deploymentName string) (map[string]string, error)
+	UpdateEnvVars(ctx context.Context, vars map[string]string, deploymentName string) error
+
+	// Dependencies
+	GetDependencies(ctx context.Context, depType string) (*DependenciesResponse, error)
+	GetDependencyInstance(ctx context.Context) (*DependencyInstancesResponse, error)
+
+	// Marketplace and generation
+	SearchApps(ctx context.Context, query string) (*SearchResponse, error)
+	ListMarketplaceApps(ctx context.Context, params map[string]string) (*SearchResponse, error)
+	InstallApp(ctx context.Context, appName, domain, name string, dryRun bool) (*InstallResponse, error)
+	PublishApp(ctx context.Context, name, description, category, author, version, repository, image string, tags []string, configFilePath string) (*AppResponse, error)
+	Gen(ctx context.Context, prompt string) (*GenResponse, error)
+}
+
+var _ DeployAjaAPI = (*APIClient)(nil)
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxRetryAfter caps how long a server-directed Retry-After is honoured
+// before the request is given up instead of retried
+const maxRetryAfter = 2 * time.Minute
+
+// RetryPolicy controls how transient API failures are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retries
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// backoff returns the jittered delay before the given retry (starting at 1)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half the delay, randomize the rest
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// requestOption customizes an outgoing request
+type requestOption func(*http.Request)
+
+// withIdempotencyKey marks a mutating request as safe to retry. The key is
+// generated once so every retry of the same call carries the same value.
+func withIdempotencyKey() requestOption {
+	key := uuid.New().String()
+	return func(req *http.Request) {
+		req.Header.Set("Idempotency-Key", key)
+	}
+}
+
+// isRetryableRequest reports whether a request may be sent more than once
+func isRetryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError reports whether a transport error is transient: timeouts,
+// refused or reset connections and connections closed mid-response. A wrong
+// certificate or a host that doesn't resolve fails the same way on every
+// attempt, so those are returned right away.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// ProxyDirect disables proxying, including proxies from HTTPS_PROXY/HTTP_PROXY
+const ProxyDirect = "direct"
+
+// TransportConfig describes how the client connects to the platform
+type TransportConfig struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string
+	// ClientCert and ClientKey are PEM files for mutual TLS
+	ClientCert string
+	ClientKey  string
+	// InsecureSkipVerify disables server certificate verification
+	InsecureSkipVerify bool
+	// RequestTimeout bounds regular requests; streaming requests are unbounded
+	RequestTimeout time.Duration
+	// Proxy is an explicit proxy URL, ProxyDirect, or empty to use the environment
+	Proxy string
+}
+
+// DefaultTransportConfig returns the settings used when none are configured
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		RequestTimeout: 30 * time.Second,
+	}
+}
+
+// NewTransport builds the HTTP transport shared by regular and streaming requests
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	switch cfg.Proxy {
+	case "":
+		transport.Proxy = http.ProxyFromEnvironment
+	case ProxyDirect:
+		transport.Proxy = nil
+	default:
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL '%s'", cfg.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file '%s'", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// ConfigureTransport replaces the client's HTTP transport and request timeout
+func (c *APIClient) ConfigureTransport(cfg TransportConfig) error {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		return err
+	}
+
+	c.HTTPClient = &http.Client{
+		Transport: transport,
+		Timeout:   cfg.RequestTimeout,
+	}
+	return nil
+}
+
+// streamClient returns a client sharing the regular transport but without
+// the overall request timeout, which would cut long-lived streams
+func (c *APIClient) streamClient() *http.Client {
+	return &http.Client{
+		Transport: c.HTTPClient.Transport,
+	}
+}
@@ -6,6 +6,71 @@ type AuthCheckResponse struct {
 	Token  string `json:"token,omitempty"`
 }
 
+// Device authorization types
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"deviceCode"`
+	UserCode                string `json:"userCode"`
+	VerificationURI         string `json:"verificationUri"`
+	VerificationURIComplete string `json:"verificationUriComplete,omitempty"`
+	ExpiresIn               int    `json:"expiresIn"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+type DeviceTokenRequest struct {
+	DeviceCode string `json:"deviceCode"`
+}
+
+type DeviceTokenResponse struct {
+	Token string `json:"token"`
+}
+
+type RefreshResponse struct {
+	Token string `json:"token"`
+}
+
+// Service account API key types
+type APIKey struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix,omitempty"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"createdAt"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes,omitempty"`
+	ExpiresIn int      `json:"expiresIn,omitempty"` // seconds, 0 means no expiry
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"` // Only returned once, at creation
+}
+
+type APIKeysResponse struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// Deployment requests carry deployaja.yaml base64 encoded
+type CostRequest struct {
+	DeploymentConfig string `json:"deploymentConfig"`
+}
+
+type DeployRequest struct {
+	DeploymentConfig string `json:"deploymentConfig"`
+	Username         string `json:"username"`
+	Password         string `json:"password"`
+	Registry         string `json:"registry"`
+	DryRun           bool   `json:"dryRun"`
+}
+
+type RollbackRequest struct {
+	DeploymentName string `json:"deploymentName"`
+}
+
 type CostResponse struct {
 	EstimatedCost struct {
 		Monthly  float64 `json:"monthly"`
@@ -99,12 +164,22 @@ type DependencyInstanceResponse struct {
 	UpdatedAt string      `json:"updatedAt"`
 }
 
+type DependencyInstancesResponse struct {
+	Instances []DependencyInstanceResponse `json:"dependenciesInstances"`
+}
+
 type ErrorResponse struct {
-	Error struct {
-		Code    string      `json:"code"`
-		Message string      `json:"message"`
-		Details interface{} `json:"details,omitempty"`
-	} `json:"error"`
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+type LogsResponse struct {
+	Logs []LogEntry `json:"logs"`
 }
 
 type LogEntry struct {
@@ -114,6 +189,15 @@ type LogEntry struct {
 	Source    string `json:"source"`
 }
 
+// Environment types, used both to read and to update variables
+type EnvRequest struct {
+	Variables map[string]string `json:"variables"`
+}
+
+type EnvResponse struct {
+	Variables map[string]string `json:"variables"`
+}
+
 type DescribeResponse struct {
 	Pod    map[string]interface{}   `json:"pod"`
 	Events []map[string]interface{} `json:"events"`
@@ -149,6 +233,21 @@ type MarketplaceApp struct {
 	Repository  string   `json:"repository,omitempty"`
 }
 
+type PublishRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Author      string   `json:"author"`
+	Version     string   `json:"version"`
+	Repository  string   `json:"repository"`
+	Image       string   `json:"image"`
+	Tags        []string `json:"tags"`
+	Config      string   `json:"config"` // Base64 encoded YAML config
+	Downloads   int      `json:"downloads"`
+	Rating      float64  `json:"rating"`
+	IsActive    bool     `json:"isActive"`
+}
+
 type AppResponse struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
@@ -158,6 +257,10 @@ type AppResponse struct {
 }
 
 // Validate types
+type ValidateRequest struct {
+	DeploymentConfig string `json:"deploymentConfig"`
+}
+
 type ValidateResponse struct {
 	Valid    bool     `json:"valid"`
 	Message  string   `json:"message"`
@@ -166,7 +269,7 @@ type ValidateResponse struct {
 
 type ValidateErrorResponse struct {
 	Valid  bool              `json:"valid"`
-	Error  ErrorResponse     `json:"error"`
+	Error  ErrorBody         `json:"error"`
 	Errors []ValidationError `json:"errors,omitempty"`
 }
 
@@ -186,6 +289,10 @@ type GenResponse struct {
 }
 
 // Restart types
+type RestartRequest struct {
+	DeploymentName string `json:"deploymentName"`
+}
+
 type RestartResponse struct {
 	Success bool        `json:"success"`
 	Data    RestartData `json:"data"`
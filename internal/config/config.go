@@ -1,12 +1,9 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -15,48 +12,85 @@ const (
 	DeployFile = "deployaja.yaml"
 )
 
-func LoadDeploymentConfig() (*DeploymentConfig, error) {
-	return LoadDeploymentConfigFromFile(DeployFile)
+// LoadDeploymentConfig loads deployaja.yaml with the overlay of env, e.g.
+// deployaja.staging.yaml, merged over it. Without env only deployaja.yaml
+// is read.
+func LoadDeploymentConfig(env string) (*DeploymentConfig, error) {
+	return LoadDeploymentConfigForEnv(DeployFile, env)
 }
 
+// LoadDeploymentConfigFromFile loads a deployment configuration, resolving
+// its variables
 func LoadDeploymentConfigFromFile(filePath string) (*DeploymentConfig, error) {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("deployment config file '%s' not found. Run 'aja init' to create one", filePath)
+	return LoadDeploymentConfigForEnv(filePath, "")
+}
+
+func LoadToken() string {
+	if os.Getenv("DEPLOYAJA_TOKEN") != "" {
+		return os.Getenv("DEPLOYAJA_TOKEN")
 	}
 
-	data, err := os.ReadFile(filePath)
+	token, err := credentialStore.Get()
 	if err != nil {
-		return nil, err
+		fmt.Fprintf(os.Stderr, "Warning: failed to read token from %s: %v\n", credentialStore, err)
+		return ""
 	}
+	return token
+}
 
-	var config DeploymentConfig
-	err = yaml.Unmarshal(data, &config)
+// SaveToken atomically replaces the stored token while holding the state lock
+func SaveToken(token string) error {
+	unlock, err := LockState(context.Background())
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer unlock()
 
-	return &config, nil
+	return saveToken(token)
 }
 
-func LoadToken() string {
-	if os.Getenv("DEPLOYAJA_TOKEN") != "" {
-		return os.Getenv("DEPLOYAJA_TOKEN")
+// UpdateToken passes the stored token to update and persists the token it
+// returns, unless another aja process stored a different one in the
+// meantime. update runs without the state lock, so a slow refresh doesn't
+// hold up other processes. A token from DEPLOYAJA_TOKEN is never persisted.
+func UpdateToken(ctx context.Context, update func(current string) (string, error)) error {
+	current := LoadToken()
+	token, err := update(current)
+	if err != nil {
+		return err
+	}
+	if token == current || os.Getenv("DEPLOYAJA_TOKEN") != "" {
+		return nil
 	}
 
-	home, _ := os.UserHomeDir()
-	tokenPath := filepath.Join(home, ConfigDir, TokenFile)
+	unlock, err := LockState(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	if data, err := os.ReadFile(tokenPath); err == nil {
-		return strings.TrimSpace(string(data))
+	stored, err := credentialStore.Get()
+	if err != nil {
+		return err
+	}
+	if stored != current {
+		// Another process refreshed the token first, both tokens are valid
+		return nil
 	}
-	return ""
+	return saveToken(token)
 }
 
-func SaveToken(token string) error {
-	home, _ := os.UserHomeDir()
-	configPath := filepath.Join(home, ConfigDir)
-	os.MkdirAll(configPath, 0755)
+// DeleteToken erases the stored token while holding the state lock
+func DeleteToken() error {
+	unlock, err := LockState(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return credentialStore.Erase()
+}
 
-	tokenPath := filepath.Join(configPath, TokenFile)
-	return os.WriteFile(tokenPath, []byte(token), 0600)
+func saveToken(token string) error {
+	return credentialStore.Store(token)
 }
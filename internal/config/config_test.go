@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useTokenFile stores tokens in a file of a temporary home for the test
+func useTokenFile(t *testing.T, token string) *FileStore {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("DEPLOYAJA_TOKEN", "")
+
+	store := NewFileStore(filepath.Join(home, ConfigDir, TokenFile))
+	if err := store.Store(token); err != nil {
+		t.Fatal(err)
+	}
+	previous := credentialStore
+	SetCredentialStore(store)
+	t.Cleanup(func() { SetCredentialStore(previous) })
+	return store
+}
+
+func TestUpdateTokenStoresTheRefreshedToken(t *testing.T) {
+	store := useTokenFile(t, "old")
+
+	err := UpdateToken(context.Background(), func(current string) (string, error) {
+		if current != "old" {
+			t.Errorf("current = %q, want the stored token", current)
+		}
+		return "new", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := store.Get(); token != "new" {
+		t.Errorf("stored token = %q, want new", token)
+	}
+}
+
+func TestUpdateTokenRefreshesWithoutTheLock(t *testing.T) {
+	store := useTokenFile(t, "old")
+
+	err := UpdateToken(context.Background(), func(current string) (string, error) {
+		// Other processes can take the lock while the token is refreshed
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		unlock, err := LockState(ctx)
+		if err != nil {
+			t.Fatalf("the state was locked during the refresh: %v", err)
+		}
+		defer unlock()
+		// and one of them refreshes first
+		if err := saveToken("other"); err != nil {
+			t.Fatal(err)
+		}
+		return "new", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := store.Get(); token != "other" {
+		t.Errorf("stored token = %q, want the one stored first kept", token)
+	}
+}
+
+func TestUpdateTokenKeepsEnvTokens(t *testing.T) {
+	store := useTokenFile(t, "stored")
+	t.Setenv("DEPLOYAJA_TOKEN", "from-env")
+
+	err := UpdateToken(context.Background(), func(current string) (string, error) {
+		if current != "from-env" {
+			t.Errorf("current = %q, want DEPLOYAJA_TOKEN", current)
+		}
+		return "new", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := store.Get(); token != "stored" {
+		t.Errorf("stored token = %q, want it left alone", token)
+	}
+}
+
+func TestLockStateStopsWaitingWhenCanceled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	unlock, err := LockState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := LockState(ctx); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CLIConfigFile = "config.yaml"
+	ContextsDir   = "contexts"
+
+	// DefaultContext is the implicit context used when none is configured.
+	// It keeps the token in the legacy ~/.deployaja/token location.
+	DefaultContext = "default"
+)
+
+// CLIConfig represents ~/.deployaja/config.yaml
+type CLIConfig struct {
+	CurrentContext  string    `yaml:"currentContext,omitempty"`
+	CredentialStore string    `yaml:"credentialStore,omitempty"`
+	Contexts        []Context `yaml:"contexts,omitempty"`
+
+	// Other settings (retries, ...) are preserved untouched
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Context is a named combination of API endpoint, token store and deployment defaults
+type Context struct {
+	Name            string          `yaml:"name"`
+	APIURL          string          `yaml:"apiUrl,omitempty"`
+	CredentialStore string          `yaml:"credentialStore,omitempty"`
+	Defaults        ContextDefaults `yaml:"defaults,omitempty"`
+}
+
+// ContextDefaults are deployment settings applied when the matching flag is not given
+type ContextDefaults struct {
+	File     string `yaml:"file,omitempty"`
+	Env      string `yaml:"env,omitempty"`
+	Registry string `yaml:"registry,omitempty"`
+}
+
+// LoadCLIConfig reads ~/.deployaja/config.yaml, returning an empty config if it doesn't exist
+func LoadCLIConfig() (*CLIConfig, error) {
+	path, err := cliConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &CLIConfig{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveCLIConfig atomically writes ~/.deployaja/config.yaml while holding the state lock
+func SaveCLIConfig(cfg *CLIConfig) error {
+	unlock, err := LockState(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0600)
+}
+
+// GetContext returns the context with the given name
+func (c *CLIConfig) GetContext(name string) (*Context, bool) {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i], true
+		}
+	}
+	return nil, false
+}
+
+// SetContext adds a context or replaces the one with the same name
+func (c *CLIConfig) SetContext(ctx Context) {
+	if existing, ok := c.GetContext(ctx.Name); ok {
+		*existing = ctx
+		return
+	}
+	c.Contexts = append(c.Contexts, ctx)
+}
+
+// DeleteContext removes the named context, reporting whether it existed
+func (c *CLIConfig) DeleteContext(name string) bool {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+			if c.CurrentContext == name {
+				c.CurrentContext = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveContext picks the active context: the explicit name if given,
+// otherwise currentContext, otherwise the implicit default context
+func (c *CLIConfig) ResolveContext(name string) (*Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return &Context{Name: DefaultContext}, nil
+	}
+
+	ctx, ok := c.GetContext(name)
+	if !ok {
+		if name == DefaultContext {
+			return &Context{Name: DefaultContext}, nil
+		}
+		return nil, fmt.Errorf("context '%s' not found. Run 'aja context list' to see available contexts", name)
+	}
+	return ctx, nil
+}
+
+// ValidateContextName rejects names that can't be used as a directory name
+func ValidateContextName(name string) error {
+	if name == "" {
+		return fmt.Errorf("context name is required")
+	}
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid context name '%s'", name)
+	}
+	return nil
+}
+
+// ContextTokenFile returns the token file name for a context relative to ~/.deployaja
+func ContextTokenFile(contextName, file string) string {
+	if contextName == "" || contextName == DefaultContext {
+		return file
+	}
+	return filepath.Join(ContextsDir, contextName, file)
+}
+
+func cliConfigPath() (string, error) {
+	configPath, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configPath, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(configPath, CLIConfigFile), nil
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	EncryptedTokenFile = "token.enc"
+
+	// Credential store kinds selectable via the credentialStore setting.
+	// Any other value names an external aja-credential-<name> helper.
+	StoreFile      = "file"
+	StoreEncrypted = "encrypted"
+
+	// CredentialHelperPrefix is prepended to a helper name to find its binary
+	CredentialHelperPrefix = "aja-credential-"
+
+	// PassphraseEnv holds the passphrase for the encrypted file store
+	PassphraseEnv = "DEPLOYAJA_CREDENTIAL_PASSPHRASE"
+
+	pbkdf2Iterations = 600000
+)
+
+// CredentialStore persists the API token
+type CredentialStore interface {
+	// Get returns the stored token, or "" when none is stored
+	Get() (string, error)
+	Store(token string) error
+	Erase() error
+	// String describes the backend for display in 'aja config'
+	String() string
+}
+
+// credentialStore is the backend used by LoadToken, SaveToken and friends
+var credentialStore CredentialStore = NewFileStore(defaultPath(TokenFile))
+
+// SetCredentialStore replaces the backend used to persist the token
+func SetCredentialStore(store CredentialStore) {
+	credentialStore = store
+}
+
+// GetCredentialStore returns the backend used to persist the token
+func GetCredentialStore() CredentialStore {
+	return credentialStore
+}
+
+// NewCredentialStore builds the backend for the given kind and context.
+// serverURL identifies the credentials when talking to an external helper.
+func NewCredentialStore(kind, contextName, serverURL string) (CredentialStore, error) {
+	switch kind {
+	case "", StoreFile:
+		return NewFileStore(defaultPath(ContextTokenFile(contextName, TokenFile))), nil
+	case StoreEncrypted:
+		return NewEncryptedFileStore(defaultPath(ContextTokenFile(contextName, EncryptedTokenFile)), os.Getenv(PassphraseEnv)), nil
+	default:
+		return NewHelperStore(kind, serverURL), nil
+	}
+}
+
+func defaultPath(name string) string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ConfigDir, name)
+}
+
+// FileStore keeps the token in plaintext, readable only by the current user
+type FileStore struct {
+	Path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+func (s *FileStore) Get() (string, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (s *FileStore) Store(token string) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, []byte(token), 0600)
+}
+
+func (s *FileStore) Erase() error {
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) String() string {
+	return fmt.Sprintf("file (%s)", s.Path)
+}
+
+// EncryptedFileStore keeps the token AES-GCM encrypted with a key derived
+// from a passphrase
+type EncryptedFileStore struct {
+	Path       string
+	Passphrase string
+}
+
+// encryptedToken is the on-disk format of EncryptedFileStore
+type encryptedToken struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func NewEncryptedFileStore(path, passphrase string) *EncryptedFileStore {
+	return &EncryptedFileStore{Path: path, Passphrase: passphrase}
+}
+
+func (s *EncryptedFileStore) Get() (string, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var enc encryptedToken
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return "", fmt.Errorf("failed to parse encrypted token file: %v", err)
+	}
+
+	gcm, err := s.cipher(enc.Salt, enc.Iterations)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := gcm.Open(nil, enc.Nonce, enc.Ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt token: wrong passphrase or corrupted file")
+	}
+	return string(plaintext), nil
+}
+
+func (s *EncryptedFileStore) Store(token string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	gcm, err := s.cipher(salt, pbkdf2Iterations)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encryptedToken{
+		Version:    1,
+		Iterations: pbkdf2Iterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, []byte(token), nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, data, 0600)
+}
+
+func (s *EncryptedFileStore) Erase() error {
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *EncryptedFileStore) String() string {
+	return fmt.Sprintf("encrypted file (%s)", s.Path)
+}
+
+func (s *EncryptedFileStore) cipher(salt []byte, iterations int) (cipher.AEAD, error) {
+	if s.Passphrase == "" {
+		return nil, fmt.Errorf("encrypted credential store requires a passphrase in %s", PassphraseEnv)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, s.Passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// HelperStore delegates to an external aja-credential-<name> binary using the
+// Docker credential helper protocol: the action (get, store or erase) is the
+// only argument and payloads are exchanged as JSON over stdin/stdout.
+type HelperStore struct {
+	Name      string
+	ServerURL string
+}
+
+// helperCredentials is the JSON payload exchanged with credential helpers
+type helperCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+func NewHelperStore(name, serverURL string) *HelperStore {
+	return &HelperStore{Name: name, ServerURL: serverURL}
+}
+
+func (s *HelperStore) Get() (string, error) {
+	out, err := s.run("get", []byte(s.ServerURL))
+	if err != nil {
+		// Helpers report missing credentials as a failure with this message
+		if strings.Contains(strings.ToLower(err.Error()), "credentials not found") {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var creds helperCredentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return "", fmt.Errorf("invalid response from %s: %v", s.binary(), err)
+	}
+	return creds.Secret, nil
+}
+
+func (s *HelperStore) Store(token string) error {
+	payload, err := json.Marshal(helperCredentials{
+		ServerURL: s.ServerURL,
+		Username:  "aja",
+		Secret:    token,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.run("store", payload)
+	return err
+}
+
+func (s *HelperStore) Erase() error {
+	_, err := s.run("erase", []byte(s.ServerURL))
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "credentials not found") {
+		return nil
+	}
+	return err
+}
+
+func (s *HelperStore) String() string {
+	return fmt.Sprintf("helper (%s)", s.binary())
+}
+
+func (s *HelperStore) binary() string {
+	return CredentialHelperPrefix + s.Name
+}
+
+func (s *HelperStore) run(action string, input []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(s.binary(), action)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stdout.String() + " " + stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("%s %s: %s", s.binary(), action, msg)
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values in deployment configs may reference variables, resolved when the
+// file is loaded:
+//
+//	${VAR}            environment variable VAR, empty when unset
+//	${VAR:-default}   default when VAR is unset or empty
+//	${file:path}      contents of a file, relative to the config file
+//	${git:sha}        commit of the repository holding the config file,
+//	                  git:short and git:branch work as well
+//	$${VAR}           a literal ${VAR}
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// gitVariables are the git: references and the git arguments printing them
+var gitVariables = map[string][]string{
+	"sha":    {"rev-parse", "HEAD"},
+	"short":  {"rev-parse", "--short", "HEAD"},
+	"branch": {"rev-parse", "--abbrev-ref", "HEAD"},
+}
+
+// ResolveVariables resolves the variables of a deployment config that isn't
+// read from a file, like one downloaded by 'aja install'. file locates
+// relative ${file:...} references and labels the diagnostics, strict makes
+// unset variables without a default errors. changed reports whether
+// resolving changed any value; cfg is nil when variables holds errors.
+func ResolveVariables(file string, data []byte, strict bool) (cfg *DeploymentConfig, changed bool, variables []Diagnostic, err error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	cfg = &DeploymentConfig{}
+	if len(doc.Content) == 0 {
+		return cfg, false, nil, nil
+	}
+
+	root := doc.Content[0]
+	variables, changed = interpolate(root, file, strict)
+	if HasErrors(variables) {
+		return nil, changed, variables, nil
+	}
+	if err := root.Decode(cfg); err != nil {
+		return nil, changed, variables, fmt.Errorf("%s: %w", file, err)
+	}
+	return cfg, changed, variables, nil
+}
+
+// interpolateDocument resolves the variables in the values of a parsed
+// file in place. Unset variables are warnings, errors when strict.
+func interpolateDocument(root *yaml.Node, file string, strict bool) []Diagnostic {
+	diagnostics, _ := interpolate(root, file, strict)
+	return diagnostics
+}
+
+// interpolate is interpolateDocument, also reporting whether any value changed
+func interpolate(root *yaml.Node, file string, strict bool) ([]Diagnostic, bool) {
+	in := &interpolator{dir: filepath.Dir(file), strict: strict, git: map[string]string{}}
+	in.node(root, "")
+
+	for i := range in.diagnostics {
+		in.diagnostics[i].File = file
+	}
+	return in.diagnostics, in.changed
+}
+
+// variablesError returns the errors among diagnostics as a single error,
+// nil if there are none
+func variablesError(diagnostics []Diagnostic) error {
+	var errs []string
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d.String())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(errs, "\n"))
+}
+
+type interpolator struct {
+	dir         string
+	git         map[string]string
+	diagnostics []Diagnostic
+	// strict makes unset variables without a default errors
+	strict bool
+	// current is the value being resolved, for the positions of diagnostics
+	current *yaml.Node
+	path    string
+	// changed is set once a value was resolved to something else
+	changed bool
+}
+
+func (in *interpolator) node(node *yaml.Node, path string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		// Keys are left as they are, they name fields
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			in.node(node.Content[i+1], joinPath(path, node.Content[i].Value))
+		}
+
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			in.node(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return
+		}
+		in.current, in.path = node, path
+		value, ok := in.expand(node.Value)
+		if !ok || value == node.Value {
+			return
+		}
+		node.Value = value
+		in.changed = true
+		// Unquoted values get the type of what they resolve to, so
+		// replicas: ${REPLICAS} is a number
+		if node.Style == 0 {
+			node.Tag = ""
+			node.Tag = node.ShortTag()
+		}
+	}
+}
+
+// expand resolves the variables in s. ok is false when one of them could
+// not be resolved.
+func (in *interpolator) expand(s string) (string, bool) {
+	var b strings.Builder
+	ok := true
+	for {
+		i := strings.Index(s, "$")
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			return b.String(), ok
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		switch {
+		case strings.HasPrefix(s, "$${"):
+			b.WriteString("${")
+			s = s[3:]
+
+		case strings.HasPrefix(s, "${"):
+			end := closingBrace(s)
+			if end < 0 {
+				in.add(SeverityError, "unterminated variable '%s', missing '}'", s)
+				return b.String() + s, false
+			}
+			value, resolved := in.resolve(s[2:end])
+			ok = ok && resolved
+			b.WriteString(value)
+			s = s[end+1:]
+
+		default:
+			b.WriteString("$")
+			s = s[1:]
+		}
+	}
+}
+
+// closingBrace returns the index of the brace closing the variable s starts
+// with, -1 if it is not closed. Defaults may hold variables themselves.
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// resolve returns the value of a variable reference, without ${ and }
+func (in *interpolator) resolve(ref string) (string, bool) {
+	if path, isFile := strings.CutPrefix(ref, "file:"); isFile {
+		return in.file(path)
+	}
+	if name, isGit := strings.CutPrefix(ref, "git:"); isGit {
+		return in.gitValue(name)
+	}
+
+	name, fallback, hasDefault := strings.Cut(ref, ":-")
+	if !variableName.MatchString(name) {
+		in.add(SeverityError, "invalid variable '${%s}', expected ${NAME}, ${NAME:-default}, ${file:path} or ${git:sha}", ref)
+		return "", false
+	}
+
+	value, set := os.LookupEnv(name)
+	switch {
+	case value != "":
+		return value, true
+	case hasDefault:
+		return in.expand(fallback)
+	case set:
+		return "", true
+	case in.strict:
+		in.add(SeverityError, "variable '%s' is not set", name)
+		return "", false
+	default:
+		in.add(SeverityWarning, "variable '%s' is not set, using an empty value", name)
+		return "", true
+	}
+}
+
+func (in *interpolator) file(path string) (string, bool) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(in.dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		in.add(SeverityError, "failed to read '%s': %v", path, err)
+		return "", false
+	}
+	return strings.TrimRight(string(data), "\r\n"), true
+}
+
+func (in *interpolator) gitValue(name string) (string, bool) {
+	args, known := gitVariables[name]
+	if !known {
+		in.add(SeverityError, "unknown variable 'git:%s', expected git:sha, git:short or git:branch", name)
+		return "", false
+	}
+	if value, cached := in.git[name]; cached {
+		return value, true
+	}
+
+	output, err := exec.Command("git", append([]string{"-C", in.dir}, args...)...).Output()
+	if err != nil {
+		if exitErr, isExit := err.(*exec.ExitError); isExit && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		in.add(SeverityError, "failed to resolve 'git:%s': %v", name, err)
+		return "", false
+	}
+	value := strings.TrimSpace(string(output))
+	in.git[name] = value
+	return value, true
+}
+
+func (in *interpolator) add(severity Severity, format string, args ...interface{}) {
+	in.diagnostics = append(in.diagnostics, Diagnostic{
+		Line:     in.current.Line,
+		Column:   in.current.Column,
+		Path:     in.path,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
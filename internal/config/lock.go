@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	LockFile = "lock"
+
+	// lockTimeout bounds how long a process waits for another one holding the state lock
+	lockTimeout  = 30 * time.Second
+	lockInterval = 50 * time.Millisecond
+)
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("state is locked")
+
+// LockState takes an exclusive cross-process lock on the ~/.deployaja state
+// directory, waiting up to lockTimeout or until ctx is done for another
+// process holding it. The returned function releases it.
+func LockState(ctx context.Context) (func(), error) {
+	configPath, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(configPath, 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(configPath, LockFile), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) || time.Now().After(deadline) {
+			f.Close()
+			if errors.Is(err, errLocked) {
+				return nil, fmt.Errorf("timed out waiting for lock on %s", f.Name())
+			}
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockInterval):
+		}
+	}
+
+	return func() {
+		unlock(f)
+		f.Close()
+	}, nil
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into
+// place, so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+func configDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ConfigDir), nil
+}
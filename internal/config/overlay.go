@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverlayFile returns the overlay of an environment for a base file:
+// deployaja.staging.yaml for deployaja.yaml and staging
+func OverlayFile(base, env string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + env + ext
+}
+
+// LoadOptions select what is merged over a deployment config file, in the
+// order of the fields
+type LoadOptions struct {
+	// Env selects the overlay, e.g. staging for deployaja.staging.yaml
+	Env string
+	// Values are files merged over the overlay like overlays are
+	Values []string
+	// Set overrides fields by path, e.g. env[LOG_LEVEL].value=debug, with
+	// values read like unquoted YAML. Entries of Set, SetString and SetFile
+	// may hold several assignments separated by commas, \, is a comma kept
+	// in a value.
+	Set []string
+	// SetString overrides fields with string values
+	SetString []string
+	// SetFile overrides fields with the contents of files, path=file
+	SetFile []string
+	// Strict makes unset ${VAR} references without a default errors
+	// instead of empty values
+	Strict bool
+}
+
+// files returns the files merged for filePath, the base file first
+func (o LoadOptions) files(filePath string) []string {
+	files := []string{filePath}
+	if o.Env != "" {
+		files = append(files, OverlayFile(filePath, o.Env))
+	}
+	return append(files, o.Values...)
+}
+
+// LoadDeploymentConfigForEnv loads a deployment configuration with the
+// overlay of env merged over it. Without env only the base file is read.
+func LoadDeploymentConfigForEnv(filePath, env string) (*DeploymentConfig, error) {
+	return LoadDeploymentConfigWithOptions(filePath, LoadOptions{Env: env})
+}
+
+// LoadDeploymentConfigWithOptions loads a deployment configuration with
+// the overlay, values files and overrides of opts applied
+func LoadDeploymentConfigWithOptions(filePath string, opts LoadOptions) (*DeploymentConfig, error) {
+	root, _, diagnostics, err := loadMergedDocument(filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := variablesError(diagnostics); err != nil {
+		return nil, err
+	}
+
+	var config DeploymentConfig
+	if root != nil {
+		if err := root.Decode(&config); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+// RenderDeploymentConfig returns the effective configuration as YAML,
+// keeping the order and comments of the files
+func RenderDeploymentConfig(filePath string, opts LoadOptions) ([]byte, error) {
+	root, _, diagnostics, err := loadMergedDocument(filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := variablesError(diagnostics); err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+// loadMergedDocument parses the base file, the overlay and the values files
+// of opts, resolves their variables, merges them and applies the overrides.
+// origins maps every node to the file it comes from and diagnostics holds
+// the problems with variables, or the syntax errors of the files, which
+// leave root nil. root is nil when the files are empty as well.
+func loadMergedDocument(filePath string, opts LoadOptions) (root *yaml.Node, origins map[*yaml.Node]string, diagnostics []Diagnostic, err error) {
+	origins = map[*yaml.Node]string{}
+	var syntax []Diagnostic
+	for i, file := range opts.files(filePath) {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			switch {
+			case i == 0:
+				return nil, nil, nil, fmt.Errorf("deployment config file '%s' not found. Run 'aja init' to create one", file)
+			case i == 1 && opts.Env != "":
+				return nil, nil, nil, fmt.Errorf("overlay '%s' of environment '%s' not found", file, opts.Env)
+			default:
+				return nil, nil, nil, fmt.Errorf("values file '%s' not found", file)
+			}
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// Syntax errors are reported for every file, with their position
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			v := &validator{file: file}
+			v.syntaxError(err)
+			syntax = append(syntax, v.diagnostics...)
+			continue
+		}
+		if len(syntax) > 0 || len(doc.Content) == 0 {
+			continue
+		}
+
+		diagnostics = append(diagnostics, interpolateDocument(doc.Content[0], file, opts.Strict)...)
+		recordOrigins(doc.Content[0], file, origins)
+		if root == nil {
+			root = doc.Content[0]
+		} else {
+			root = mergeNodes(root, doc.Content[0])
+		}
+	}
+
+	if len(syntax) > 0 {
+		return nil, origins, syntax, nil
+	}
+
+	if len(opts.Set)+len(opts.SetString)+len(opts.SetFile) > 0 {
+		if root == nil {
+			root = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		if err := applyOverrides(root, opts, origins); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return root, origins, diagnostics, nil
+}
+
+func recordOrigins(node *yaml.Node, file string, origins map[*yaml.Node]string) {
+	origins[node] = file
+	for _, child := range node.Content {
+		recordOrigins(child, file, origins)
+	}
+}
+
+// mergeNodes deep-merges overlay over base. Mappings are merged key by key
+// and lists of named items, like env and dependencies, item by item. Any
+// other overlay value replaces the base one.
+func mergeNodes(base, overlay *yaml.Node) *yaml.Node {
+	switch {
+	case base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			if j := mappingIndex(base, key.Value); j >= 0 {
+				base.Content[j+1] = mergeNodes(base.Content[j+1], value)
+			} else {
+				base.Content = append(base.Content, key, value)
+			}
+		}
+		return base
+
+	case base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode && namedItems(base) && namedItems(overlay):
+		for _, item := range overlay.Content {
+			name := itemName(item)
+			merged := false
+			for i, existing := range base.Content {
+				if itemName(existing) == name {
+					base.Content[i] = mergeNodes(existing, item)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				base.Content = append(base.Content, item)
+			}
+		}
+		return base
+
+	default:
+		return overlay
+	}
+}
+
+// mappingIndex returns the index of the key in a mapping node, -1 if absent
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// namedItems reports whether every item of a list is a mapping with a name
+func namedItems(sequence *yaml.Node) bool {
+	for _, item := range sequence.Content {
+		if itemName(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func itemName(item *yaml.Node) string {
+	if item.Kind != yaml.MappingNode {
+		return ""
+	}
+	if i := mappingIndex(item, "name"); i >= 0 {
+		return item.Content[i+1].Value
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeNodes(t *testing.T) {
+	overlay := `name: shop-prod
+resources:
+  replicas: 3
+env:
+  - name: LOG_LEVEL
+    value: warn
+  - name: REGION
+    value: eu
+dependencies:
+  - name: db
+    version: "16"
+`
+	var base, over yaml.Node
+	if err := yaml.Unmarshal([]byte(overrideBase), &base); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(overlay), &over); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg DeploymentConfig
+	if err := mergeNodes(base.Content[0], over.Content[0]).Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Name != "shop-prod" || cfg.Container.Image != "acme/shop:1.0" || cfg.Resources.Replicas != 3 {
+		t.Errorf("got name %q, image %q, replicas %d, want scalars replaced and other fields kept", cfg.Name, cfg.Container.Image, cfg.Resources.Replicas)
+	}
+	wantEnv := []EnvVar{{Name: "LOG_LEVEL", Value: "warn"}, {Name: "REGION", Value: "eu"}}
+	if !reflect.DeepEqual(cfg.Env, wantEnv) {
+		t.Errorf("env = %+v, want %+v merged by name", cfg.Env, wantEnv)
+	}
+	if len(cfg.Dependencies) != 1 || cfg.Dependencies[0].Type != "postgresql" || cfg.Dependencies[0].Version != "16" {
+		t.Errorf("dependencies = %+v, want db merged keeping its type", cfg.Dependencies)
+	}
+}
+
+func TestMergeNodesReplacesUnnamedLists(t *testing.T) {
+	var base, over yaml.Node
+	if err := yaml.Unmarshal([]byte("envMap:\n  A: a\nvolumes:\n  - size: 1Gi\n"), &base); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte("envMap:\n  B: b\nvolumes:\n  - size: 2Gi\n"), &over); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg DeploymentConfig
+	if err := mergeNodes(base.Content[0], over.Content[0]).Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Volumes) != 1 || cfg.Volumes[0].Size != "2Gi" {
+		t.Errorf("volumes = %+v, want the overlay list", cfg.Volumes)
+	}
+	if !reflect.DeepEqual(cfg.EnvMap, map[string]string{"A": "a", "B": "b"}) {
+		t.Errorf("envMap = %v, want both keys", cfg.EnvMap)
+	}
+}
@@ -0,0 +1,357 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setOrigin labels the diagnostics of values set on the command line
+const setOrigin = "--set"
+
+// pathSegment is a step of a field path: a field or map key, or the
+// [index] or [name] of a list item
+type pathSegment struct {
+	key     string
+	bracket bool
+}
+
+// parseFieldPath splits a path like env[LOG_LEVEL].value or
+// dockerConfig.auths[ghcr.io].username into its segments
+func parseFieldPath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	rest := path
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path '%s': missing ']'", path)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("invalid path '%s': empty []", path)
+			}
+			segments = append(segments, pathSegment{key: rest[1:end], bracket: true})
+			rest = rest[end+1:]
+			if rest != "" && rest[0] != '.' && rest[0] != '[' {
+				return nil, fmt.Errorf("invalid path '%s': expected '.' or '[' after ']'", path)
+			}
+
+		case rest[0] == '.':
+			if len(segments) == 0 || len(rest) == 1 || rest[1] == '.' || rest[1] == '[' {
+				return nil, fmt.Errorf("invalid path '%s'", path)
+			}
+			rest = rest[1:]
+
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, pathSegment{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segments, nil
+}
+
+// applyOverrides applies the --set, --set-string and --set-file values of
+// opts to root, in that order
+func applyOverrides(root *yaml.Node, opts LoadOptions, origins map[*yaml.Node]string) error {
+	type override struct {
+		flag   string
+		values []string
+		typed  bool
+	}
+	for _, o := range []override{
+		{"--set", opts.Set, true},
+		{"--set-string", opts.SetString, false},
+		{"--set-file", opts.SetFile, false},
+	} {
+		for _, assignment := range splitAssignments(o.values) {
+			path, value, found := strings.Cut(assignment, "=")
+			path, value = strings.TrimSpace(path), strings.TrimSpace(value)
+			if !found || path == "" {
+				return fmt.Errorf("invalid %s '%s', expected path=value", o.flag, assignment)
+			}
+
+			if o.flag == "--set-file" {
+				data, err := os.ReadFile(value)
+				if err != nil {
+					return fmt.Errorf("%s %s: %w", o.flag, path, err)
+				}
+				value = string(data)
+			}
+
+			// The errors name the path
+			if err := setField(root, path, value, o.typed, origins); err != nil {
+				return fmt.Errorf("%s: %w", o.flag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// splitAssignments splits flag values holding comma separated assignments,
+// a=1,b=2, into one assignment each. \, is a comma kept in the value.
+func splitAssignments(values []string) []string {
+	var assignments []string
+	for _, value := range values {
+		var b strings.Builder
+		for i := 0; i < len(value); i++ {
+			switch {
+			case strings.HasPrefix(value[i:], `\,`):
+				b.WriteByte(',')
+				i++
+			case value[i] == ',':
+				assignments = append(assignments, b.String())
+				b.Reset()
+			default:
+				b.WriteByte(value[i])
+			}
+		}
+		assignments = append(assignments, b.String())
+	}
+	return assignments
+}
+
+// setField sets the field at path, creating the fields and list items
+// leading to it. Typed values are read like unquoted YAML and must match
+// the type of the field, others are strings.
+func setField(root *yaml.Node, path, value string, typed bool, origins map[*yaml.Node]string) error {
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return err
+	}
+
+	node, t := root, reflect.TypeOf(DeploymentConfig{})
+	done := ""
+	for i, segment := range segments {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		next := newNodeFor(t)
+		if node.Kind != next.Kind && node.Kind != yaml.ScalarNode {
+			return fmt.Errorf("%s is %s", pathOrRoot(done), describeNode(node))
+		}
+		if node.Kind == yaml.ScalarNode {
+			if node.Tag != "!!null" || next.Kind == yaml.ScalarNode {
+				return fmt.Errorf("%s is %s, it has no field '%s'", pathOrRoot(done), describeNode(node), segment.key)
+			}
+			// An empty field, like env: without items
+			*node = *next
+			origins[node] = setOrigin
+		}
+
+		var child *yaml.Node
+		switch t.Kind() {
+		case reflect.Struct:
+			if segment.bracket {
+				return fmt.Errorf("%s is a mapping, use %s", pathOrRoot(done), joinPath(done, segment.key))
+			}
+			fields := yamlFields(t)
+			fieldType, known := fields[segment.key]
+			if !known {
+				names := make([]string, 0, len(fields))
+				for name := range fields {
+					names = append(names, name)
+				}
+				if suggestion := closest(segment.key, names); suggestion != "" {
+					return fmt.Errorf("unknown field '%s' (did you mean %s?)", joinPath(done, segment.key), joinPath(done, suggestion))
+				}
+				return fmt.Errorf("unknown field '%s'", joinPath(done, segment.key))
+			}
+			child = mappingValue(node, segment.key, fieldType, origins)
+			t = fieldType
+			done = joinPath(done, segment.key)
+
+		case reflect.Map:
+			child = mappingValue(node, segment.key, t.Elem(), origins)
+			t = t.Elem()
+			done = joinPath(done, segment.key)
+
+		case reflect.Slice:
+			if !segment.bracket {
+				return fmt.Errorf("%s is a list, use %s[INDEX] or %s[NAME]", done, done, done)
+			}
+			child, err = listItem(node, segment.key, t.Elem(), origins)
+			if err != nil {
+				return fmt.Errorf("%s: %w", done, err)
+			}
+			t = t.Elem()
+			done = fmt.Sprintf("%s[%s]", done, segment.key)
+
+		case reflect.Interface:
+			// Free-form settings, like dependency config
+			child = mappingValue(node, segment.key, t, origins)
+			done = joinPath(done, segment.key)
+
+		default:
+			return fmt.Errorf("%s is %s, it has no field '%s'", done, describeNode(node), segment.key)
+		}
+
+		if i == len(segments)-1 {
+			scalar, err := coerceValue(value, t, typed)
+			if err != nil {
+				return fmt.Errorf("%s %w", done, err)
+			}
+			*child = *scalar
+			origins[child] = setOrigin
+		}
+		node = child
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, adding an empty
+// value for type t when the key is missing
+func mappingValue(mapping *yaml.Node, key string, t reflect.Type, origins map[*yaml.Node]string) *yaml.Node {
+	if i := mappingIndex(mapping, key); i >= 0 {
+		value := mapping.Content[i+1]
+		if value.Kind == yaml.AliasNode {
+			// Don't change what else refers to the anchor
+			copied := *value.Alias
+			value = &copied
+			mapping.Content[i+1] = value
+		}
+		return value
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	value := newNodeFor(t)
+	origins[keyNode], origins[value] = setOrigin, setOrigin
+	mapping.Content = append(mapping.Content, keyNode, value)
+	return value
+}
+
+// listItem returns the item of a list at an index, or with a name. Items
+// are also found by type, so dependencies[postgresql] finds the only
+// postgresql dependency. Named items that don't exist are added, as is an
+// item at the index just past the end.
+func listItem(list *yaml.Node, key string, t reflect.Type, origins map[*yaml.Node]string) (*yaml.Node, error) {
+	if index, err := strconv.Atoi(key); err == nil {
+		switch {
+		case index >= 0 && index < len(list.Content):
+			return list.Content[index], nil
+		case index == len(list.Content):
+			item := newNodeFor(t)
+			origins[item] = setOrigin
+			list.Content = append(list.Content, item)
+			return item, nil
+		default:
+			return nil, fmt.Errorf("index %d is out of range, the list has %d items", index, len(list.Content))
+		}
+	}
+
+	for _, item := range list.Content {
+		if itemName(item) == key {
+			return item, nil
+		}
+	}
+	var byType []*yaml.Node
+	for _, item := range list.Content {
+		if item.Kind == yaml.MappingNode {
+			if i := mappingIndex(item, "type"); i >= 0 && item.Content[i+1].Value == key {
+				byType = append(byType, item)
+			}
+		}
+	}
+	switch len(byType) {
+	case 1:
+		return byType[0], nil
+	case 0:
+	default:
+		return nil, fmt.Errorf("%d items have type '%s', select one by name", len(byType), key)
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("no item '%s', items are selected by index", key)
+	}
+	if _, named := yamlFields(t)["name"]; !named {
+		return nil, fmt.Errorf("no item '%s', items are selected by index", key)
+	}
+	item := newNodeFor(t)
+	name := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	nameKey := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "name"}
+	item.Content = append(item.Content, nameKey, name)
+	recordOrigins(item, setOrigin, origins)
+	list.Content = append(list.Content, item)
+	return item, nil
+}
+
+// newNodeFor returns an empty node for a value of type t
+func newNodeFor(t reflect.Type) *yaml.Node {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	case reflect.Slice:
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	}
+}
+
+// coerceValue returns the node of a value set for a field of type t
+func coerceValue(value string, t reflect.Type, typed bool) (*yaml.Node, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	tag := node.ShortTag()
+
+	switch t.Kind() {
+	case reflect.String:
+		node.Tag = "!!str"
+
+	case reflect.Interface:
+		node.Tag = "!!str"
+		if typed {
+			node.Tag = tag
+		}
+
+	case reflect.Int:
+		if !typed {
+			return nil, fmt.Errorf("is a number, set it with --set")
+		}
+		if tag != "!!int" {
+			return nil, fmt.Errorf("must be a whole number, got '%s'", value)
+		}
+		node.Tag = tag
+
+	case reflect.Bool:
+		if !typed {
+			return nil, fmt.Errorf("is true or false, set it with --set")
+		}
+		if tag != "!!bool" {
+			return nil, fmt.Errorf("must be true or false, got '%s'", value)
+		}
+		node.Tag = tag
+
+	case reflect.Slice:
+		return nil, fmt.Errorf("is a list, set its items by [INDEX] or [NAME] or use --values")
+
+	default:
+		return nil, fmt.Errorf("is a mapping, set its fields or use --values")
+	}
+	return node, nil
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "the config"
+	}
+	return path
+}
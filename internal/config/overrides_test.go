@@ -0,0 +1,155 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []pathSegment
+	}{
+		{"name", []pathSegment{{key: "name"}}},
+		{"resources.replicas", []pathSegment{{key: "resources"}, {key: "replicas"}}},
+		{"env[LOG_LEVEL].value", []pathSegment{{key: "env"}, {key: "LOG_LEVEL", bracket: true}, {key: "value"}}},
+		{"volumes[0].size", []pathSegment{{key: "volumes"}, {key: "0", bracket: true}, {key: "size"}}},
+		{"dockerConfig.auths[ghcr.io].username", []pathSegment{{key: "dockerConfig"}, {key: "auths"}, {key: "ghcr.io", bracket: true}, {key: "username"}}},
+		{"a[b][c]", []pathSegment{{key: "a"}, {key: "b", bracket: true}, {key: "c", bracket: true}}},
+	}
+	for _, tt := range tests {
+		got, err := parseFieldPath(tt.path)
+		if err != nil {
+			t.Errorf("parseFieldPath(%q): %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFieldPath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseFieldPathErrors(t *testing.T) {
+	for _, path := range []string{"", ".name", "name.", "a..b", "a.[0]", "env[", "env[]", "env[a]b"} {
+		if got, err := parseFieldPath(path); err == nil {
+			t.Errorf("parseFieldPath(%q) = %+v, want an error", path, got)
+		}
+	}
+}
+
+// applySet parses data, sets path to value and decodes the result
+func applySet(t *testing.T, data, path, value string, typed bool) (*DeploymentConfig, error) {
+	t.Helper()
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatal(err)
+	}
+	root := doc.Content[0]
+	if err := setField(root, path, value, typed, map[*yaml.Node]string{}); err != nil {
+		return nil, err
+	}
+	var cfg DeploymentConfig
+	if err := root.Decode(&cfg); err != nil {
+		t.Fatalf("decoding after setting %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+const overrideBase = `name: shop
+container:
+  image: acme/shop:1.0
+env:
+  - name: LOG_LEVEL
+    value: info
+dependencies:
+  - name: db
+    type: postgresql
+    version: "15"
+`
+
+func TestSetField(t *testing.T) {
+	cfg, err := applySet(t, overrideBase, "env[LOG_LEVEL].value", "debug", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Env) != 1 || cfg.Env[0].Value != "debug" {
+		t.Errorf("env = %+v, want LOG_LEVEL=debug", cfg.Env)
+	}
+
+	cfg, err = applySet(t, overrideBase, "env[REGION].value", "eu,us", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Env) != 2 || cfg.Env[1] != (EnvVar{Name: "REGION", Value: "eu,us"}) {
+		t.Errorf("env = %+v, want REGION=eu,us added", cfg.Env)
+	}
+
+	cfg, err = applySet(t, overrideBase, "dependencies[postgresql].version", "16", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Dependencies[0].Version != "16" {
+		t.Errorf("version = %q, want 16 set on the dependency selected by type", cfg.Dependencies[0].Version)
+	}
+
+	cfg, err = applySet(t, overrideBase, "resources.replicas", "3", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Resources.Replicas != 3 {
+		t.Errorf("replicas = %d, want 3 in a created mapping", cfg.Resources.Replicas)
+	}
+
+	cfg, err = applySet(t, overrideBase, "dockerConfig.auths[ghcr.io].username", "bot", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DockerConfig == nil || cfg.DockerConfig.Auths["ghcr.io"].Username != "bot" {
+		t.Errorf("dockerConfig = %+v, want the ghcr.io username set", cfg.DockerConfig)
+	}
+
+	cfg, err = applySet(t, overrideBase, "container.image", "007", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Container.Image != "007" {
+		t.Errorf("image = %q, want the string 007", cfg.Container.Image)
+	}
+}
+
+func TestSetFieldErrors(t *testing.T) {
+	tests := []struct {
+		path, value string
+		typed       bool
+		want        string
+	}{
+		{"resources.replicas", "three", true, "must be a whole number"},
+		{"resources.replicas", "3", false, "set it with --set"},
+		{"contaner.image", "x", true, "did you mean container"},
+		{"env.LOG_LEVEL", "x", true, "env is a list"},
+		{"env[5].value", "x", true, "out of range"},
+		{"volumes[data].size", "1Gi", true, ""},
+		{"container", "x", true, "is a mapping"},
+		{"name.first", "x", true, "it has no field 'first'"},
+	}
+	for _, tt := range tests {
+		_, err := applySet(t, overrideBase, tt.path, tt.value, tt.typed)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("setting %s: %v", tt.path, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("setting %s = %s: err = %v, want one containing %q", tt.path, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestSplitAssignments(t *testing.T) {
+	got := splitAssignments([]string{`a=1,b=2`, `env[REGIONS].value=eu\,us`, `c=`})
+	want := []string{"a=1", "b=2", "env[REGIONS].value=eu,us", "c="}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitAssignments = %q, want %q", got, want)
+	}
+}
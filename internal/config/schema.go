@@ -0,0 +1,213 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+const (
+	// SchemaURL is where the JSON Schema of deployaja.yaml is published,
+	// generated into schema/deployaja.schema.json by 'aja schema'
+	SchemaURL = "https://raw.githubusercontent.com/deployaja/deployaja-cli/main/schema/deployaja.schema.json"
+
+	// SchemaModeline tells the YAML language server, used by VS Code and
+	// other editors, which schema validates the file
+	SchemaModeline = "# yaml-language-server: $schema=" + SchemaURL
+)
+
+// JSONSchema is the subset of JSON Schema draft 2020-12 used to describe
+// deployaja.yaml
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Type is a string, or a list of them for scalars YAML accepts unquoted
+	Type                 interface{}            `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Maximum              *int                   `json:"maximum,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Examples             []interface{}          `json:"examples,omitempty"`
+}
+
+// schemaField describes a field of deployaja.yaml beyond its Go type
+type schemaField struct {
+	description string
+	required    bool
+	// quantity fields take Kubernetes resource quantities
+	quantity bool
+	// anyScalar fields are strings that are often written unquoted, like
+	// versions and env values
+	anyScalar bool
+	enum      []string
+	pattern   string
+	minimum   *int
+	maximum   *int
+	maxLength *int
+	examples  []interface{}
+}
+
+// schemaFields are keyed by field path, list items are written as []
+var schemaFields = map[string]schemaField{
+	"name": {
+		description: "Name of the deployment, a lowercase DNS label",
+		required:    true,
+		pattern:     DeploymentNamePattern.String(),
+		maxLength:   intPtr(63),
+		examples:    []interface{}{"my-app"},
+	},
+	"description": {description: "Free text description of the application"},
+	"container": {
+		description: "The container to run",
+		required:    true,
+	},
+	"container.image": {
+		description: "Container image, with an optional registry and tag",
+		required:    true,
+		examples:    []interface{}{"nginx:latest", "ghcr.io/acme/api:1.4.2"},
+	},
+	"container.port": {
+		description: "Port the container listens on",
+		minimum:     intPtr(1),
+		maximum:     intPtr(65535),
+	},
+	"resources":          {description: "Compute resources of each replica"},
+	"resources.cpu":      {description: "CPU request, in cores or millicores", quantity: true, examples: []interface{}{"500m", "1"}},
+	"resources.memory":   {description: "Memory request", quantity: true, examples: []interface{}{"512Mi", "1Gi"}},
+	"resources.replicas": {description: "Number of instances", minimum: intPtr(0)},
+	"dependencies":       {description: "Managed services provisioned with the application, their connection strings are injected as environment variables"},
+	"dependencies[].name": {
+		description: "Name of the dependency, prefixes the injected variables",
+		required:    true,
+	},
+	"dependencies[].type": {
+		description: "Kind of managed service",
+		required:    true,
+		enum:        DependencyTypes,
+	},
+	"dependencies[].version": {description: "Version of the service, the platform default when empty", anyScalar: true, examples: []interface{}{"15", "7"}},
+	"dependencies[].config":  {description: "Service specific settings"},
+	"dependencies[].storage": {description: "Storage size", quantity: true, examples: []interface{}{"1Gi"}},
+	"env":                    {description: "Environment variables of the container"},
+	"env[].name":             {description: "Variable name", required: true},
+	"env[].value":            {description: "Variable value", anyScalar: true},
+	"env[].userManaged":      {description: "Set by 'aja env', kept when the file is deployed again"},
+	"healthCheck":            {description: "HTTP health check of the container"},
+	"healthCheck.path": {
+		description: "Path requested by the health check",
+		pattern:     "^/",
+		examples:    []interface{}{"/health"},
+	},
+	"healthCheck.port": {
+		description: "Port of the health check, the container port when empty",
+		minimum:     intPtr(1),
+		maximum:     intPtr(65535),
+	},
+	"healthCheck.initialDelaySeconds": {description: "Seconds to wait after start before the first check", minimum: intPtr(0)},
+	"healthCheck.periodSeconds":       {description: "Seconds between checks", minimum: intPtr(0)},
+	"domain":                          {description: "Custom domain of the application, a deployaja.id subdomain when empty", examples: []interface{}{"app.example.com"}},
+	"volumes":                         {description: "Persistent volumes mounted into the container"},
+	"volumes[].name":                  {description: "Volume name", required: true},
+	"volumes[].size":                  {description: "Volume size", quantity: true, examples: []interface{}{"1Gi"}},
+	"volumes[].mountPath": {
+		description: "Absolute path the volume is mounted at",
+		required:    true,
+		pattern:     "^/",
+	},
+	"envMap":                        {description: "Environment variables as a map, merged with env"},
+	"dockerConfig":                  {description: "Credentials of private registries, in the format of ~/.docker/config.json"},
+	"dockerConfig.auths":            {description: "Credentials by registry host"},
+	"dockerConfig.auths[].username": {description: "Registry username"},
+	"dockerConfig.auths[].password": {description: "Registry password"},
+	"dockerConfig.auths[].email":    {description: "Registry account email"},
+	"dockerConfig.auths[].auth":     {description: "base64 of username:password, instead of username and password"},
+	"envMap[]":                      {anyScalar: true},
+}
+
+// DeploymentConfigSchema returns the JSON Schema of deployaja.yaml,
+// generated from DeploymentConfig
+func DeploymentConfigSchema() *JSONSchema {
+	schema := schemaFor(reflect.TypeOf(DeploymentConfig{}), "")
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.ID = SchemaURL
+	schema.Title = "deployaja.yaml"
+	schema.Description = "Deployment configuration of a DeployAja application"
+	return schema
+}
+
+// MarshalDeploymentConfigSchema returns the schema as indented JSON
+func MarshalDeploymentConfigSchema() ([]byte, error) {
+	data, err := json.MarshalIndent(DeploymentConfigSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaFor describes the Go type of the field at path
+func schemaFor(t reflect.Type, path string) *JSONSchema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	field := schemaFields[path]
+	schema := &JSONSchema{Description: field.description}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema.Type = "object"
+		schema.Properties = map[string]*JSONSchema{}
+		schema.AdditionalProperties = false
+		for name, fieldType := range yamlFields(t) {
+			fieldPath := joinPath(path, name)
+			schema.Properties[name] = schemaFor(fieldType, fieldPath)
+			if schemaFields[fieldPath].required {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		slices.Sort(schema.Required)
+
+	case reflect.Map:
+		schema.Type = "object"
+		if t.Elem().Kind() != reflect.Interface {
+			schema.AdditionalProperties = schemaFor(t.Elem(), path+"[]")
+		}
+
+	case reflect.Slice:
+		schema.Type = "array"
+		schema.Items = schemaFor(t.Elem(), path+"[]")
+
+	case reflect.Int:
+		schema.Type = "integer"
+		schema.Minimum = field.minimum
+		schema.Maximum = field.maximum
+
+	case reflect.Bool:
+		schema.Type = "boolean"
+
+	case reflect.String:
+		schema.Type = "string"
+		if field.anyScalar {
+			schema.Type = []string{"string", "number", "boolean"}
+		}
+		schema.Enum = field.enum
+		schema.Pattern = field.pattern
+		if field.quantity {
+			schema.Pattern = quantityPattern.String()
+		}
+		schema.MaxLength = field.maxLength
+	}
+
+	schema.Examples = field.examples
+	return schema
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,521 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// DeploymentNamePattern is the DNS label deployment names must match
+	DeploymentNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+	// DependencyTypes are the managed services the platform provides
+	DependencyTypes = []string{"postgresql", "mysql", "redis", "rabbitmq", "mongodb", "elasticsearch", "memcached"}
+
+	// quantityPattern matches Kubernetes resource quantities like 500m or 1Gi
+	quantityPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+	// nameSeparators are the runs of characters suggestName replaces with '-'
+	nameSeparators = regexp.MustCompile(`[^a-z0-9-]+`)
+	// yamlErrorLine finds the line in yaml.v3 syntax errors
+	yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+)
+
+// Severity of a Diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a deployment configuration. Line and
+// Column are 1-based, zero when the problem has no position.
+type Diagnostic struct {
+	File       string
+	Line       int
+	Column     int
+	Path       string
+	Severity   Severity
+	Message    string
+	Suggestion string
+}
+
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	b.WriteString(d.File)
+	if d.Line > 0 {
+		fmt.Fprintf(&b, ":%d:%d", d.Line, d.Column)
+	}
+	fmt.Fprintf(&b, ": %s: ", d.Severity)
+	if d.Path != "" {
+		b.WriteString(d.Path + ": ")
+	}
+	b.WriteString(d.Message)
+	if d.Suggestion != "" {
+		fmt.Fprintf(&b, " (did you mean %s?)", d.Suggestion)
+	}
+	return b.String()
+}
+
+// ValidationError is returned for configurations with errors. It lists
+// every problem found, warnings included.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	errs := 0
+	for _, d := range e.Diagnostics {
+		if d.Severity == SeverityError {
+			errs++
+		}
+	}
+	if errs == 1 {
+		return "configuration has 1 error"
+	}
+	return fmt.Sprintf("configuration has %d errors", errs)
+}
+
+// HasErrors reports whether any of the diagnostics is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	return slices.ContainsFunc(diagnostics, func(d Diagnostic) bool {
+		return d.Severity == SeverityError
+	})
+}
+
+// ValidateDeploymentConfigFile checks a deployment configuration file, with
+// the overlay, values files and overrides of opts applied, without
+// contacting the platform: unknown and duplicate keys, values of the wrong
+// type and the values the platform would reject, after resolving variables.
+// Diagnostics name the file each problem is in.
+// The error is set when a file can't be read or an override doesn't apply.
+func ValidateDeploymentConfigFile(filePath string, opts LoadOptions) ([]Diagnostic, error) {
+	_, diagnostics, err := LoadValidatedDeploymentConfig(filePath, opts)
+	return diagnostics, err
+}
+
+// LoadValidatedDeploymentConfig checks a deployment configuration file like
+// ValidateDeploymentConfigFile and decodes the document it checked, so the
+// files are read, and the variables and overrides applied, only once. The
+// config is nil when the diagnostics hold errors.
+func LoadValidatedDeploymentConfig(filePath string, opts LoadOptions) (*DeploymentConfig, []Diagnostic, error) {
+	root, origins, diagnostics, err := loadMergedDocument(filePath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Syntax errors leave nothing to check, and values of unresolved
+	// variables would be reported as wrong
+	if HasErrors(diagnostics) {
+		return nil, diagnostics, nil
+	}
+
+	v := &validator{file: filePath, origins: origins, nodes: map[string]*yaml.Node{}, diagnostics: diagnostics}
+	v.validate(root)
+	if HasErrors(v.diagnostics) {
+		return nil, v.diagnostics, nil
+	}
+
+	var cfg DeploymentConfig
+	if err := root.Decode(&cfg); err != nil {
+		return nil, v.diagnostics, err
+	}
+	return &cfg, v.diagnostics, nil
+}
+
+// validate checks the root node of a configuration, nil for empty files
+func (v *validator) validate(root *yaml.Node) {
+	if root == nil {
+		v.add(nil, "", "the file is empty")
+		return
+	}
+
+	v.nodes[""] = root
+	v.walk(root, reflect.TypeOf(DeploymentConfig{}), "")
+
+	// Values of the wrong type are left out of cfg and were reported by
+	// walk already, the checks skip them. Duplicate keys were reported too,
+	// but would stop the mapping they are in from being decoded.
+	var cfg DeploymentConfig
+	var typeErr *yaml.TypeError
+	if err := withoutDuplicateKeys(root).Decode(&cfg); err != nil && !errors.As(err, &typeErr) {
+		v.add(root, "", "%v", err)
+		return
+	}
+	v.checking = true
+	v.check(&cfg)
+
+	// Problems without a position come first, the base file before overlays
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i], v.diagnostics[j]
+		if a.File != b.File {
+			return a.File == v.file
+		}
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+}
+
+type validator struct {
+	file        string
+	diagnostics []Diagnostic
+	// origins maps the nodes of merged files to their file, nodes missing
+	// from it belong to file
+	origins map[*yaml.Node]string
+	// nodes holds the node of every field path seen, for the positions of
+	// the value checks
+	nodes map[string]*yaml.Node
+	// checking is set while the values are checked, after the structure
+	checking bool
+}
+
+func (v *validator) report(node *yaml.Node, path string, severity Severity, suggestion, format string, args ...interface{}) {
+	if v.checking && v.failed(path) {
+		return
+	}
+
+	d := Diagnostic{
+		File:       v.file,
+		Path:       path,
+		Severity:   severity,
+		Message:    fmt.Sprintf(format, args...),
+		Suggestion: suggestion,
+	}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+		if origin, exists := v.origins[node]; exists {
+			d.File = origin
+		}
+	}
+	v.diagnostics = append(v.diagnostics, d)
+}
+
+// failed reports whether the structure of path or of a field containing it
+// is wrong, its value then isn't what the file says
+func (v *validator) failed(path string) bool {
+	for _, d := range v.diagnostics {
+		if d.Severity != SeverityError {
+			continue
+		}
+		if d.Path == "" || d.Path == path || strings.HasPrefix(path, d.Path+".") || strings.HasPrefix(path, d.Path+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) add(node *yaml.Node, path, format string, args ...interface{}) {
+	v.report(node, path, SeverityError, "", format, args...)
+}
+
+func (v *validator) syntaxError(err error) {
+	d := Diagnostic{File: v.file, Severity: SeverityError, Message: err.Error()}
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Column = 1
+		d.Message = m[2]
+	}
+	v.diagnostics = append(v.diagnostics, d)
+}
+
+// walk checks node against the Go type it is decoded into
+func (v *validator) walk(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.add(node, path, "must be a mapping, got %s", describeNode(node))
+			return
+		}
+		fields := yamlFields(t)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+			if seen[key.Value] {
+				v.add(key, keyPath, "duplicate key")
+				continue
+			}
+			seen[key.Value] = true
+
+			field, known := fields[key.Value]
+			if !known {
+				v.report(key, keyPath, SeverityError, closest(key.Value, names), "unknown field")
+				continue
+			}
+			v.nodes[keyPath] = value
+			v.walk(value, field, keyPath)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.add(node, path, "must be a mapping, got %s", describeNode(node))
+			return
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+			if seen[key.Value] {
+				v.add(key, keyPath, "duplicate key")
+				continue
+			}
+			seen[key.Value] = true
+			v.nodes[keyPath] = value
+			v.walk(value, t.Elem(), keyPath)
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.add(node, path, "must be a list, got %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			v.nodes[itemPath] = item
+			v.walk(item, t.Elem(), itemPath)
+		}
+
+	case reflect.Int:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			v.add(node, path, "must be a whole number, got %s", describeNode(node))
+		}
+
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			v.add(node, path, "must be true or false, got %s", describeNode(node))
+		}
+
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			v.add(node, path, "must be a string, got %s", describeNode(node))
+		}
+	}
+}
+
+// check validates the values like the platform does
+func (v *validator) check(cfg *DeploymentConfig) {
+	switch {
+	case cfg.Name == "":
+		v.missing("name")
+	case len(cfg.Name) > 63 || !DeploymentNamePattern.MatchString(cfg.Name):
+		v.report(v.nodes["name"], "name", SeverityError, suggestName(cfg.Name),
+			"must be at most 63 lowercase letters, digits and '-', starting and ending with a letter or digit")
+	}
+
+	if cfg.Container.Image == "" {
+		v.missing("container.image")
+	}
+	v.checkPort("container.port", cfg.Container.Port)
+	v.checkPort("healthCheck.port", cfg.HealthCheck.Port)
+	if cfg.HealthCheck.Path != "" && !strings.HasPrefix(cfg.HealthCheck.Path, "/") {
+		v.report(v.nodes["healthCheck.path"], "healthCheck.path", SeverityError, "/"+cfg.HealthCheck.Path, "must start with '/'")
+	}
+	if cfg.HealthCheck.InitialDelaySeconds < 0 {
+		v.add(v.nodes["healthCheck.initialDelaySeconds"], "healthCheck.initialDelaySeconds", "must not be negative")
+	}
+	if cfg.HealthCheck.PeriodSeconds < 0 {
+		v.add(v.nodes["healthCheck.periodSeconds"], "healthCheck.periodSeconds", "must not be negative")
+	}
+
+	if cfg.Resources.Replicas < 0 {
+		v.add(v.nodes["resources.replicas"], "resources.replicas", "must not be negative")
+	}
+	v.checkQuantity("resources.cpu", cfg.Resources.CPU)
+	v.checkQuantity("resources.memory", cfg.Resources.Memory)
+
+	for i, dep := range cfg.Dependencies {
+		path := fmt.Sprintf("dependencies[%d]", i)
+		if dep.Name == "" {
+			v.missing(path + ".name")
+		}
+		switch {
+		case dep.Type == "":
+			v.missing(path + ".type")
+		case !slices.Contains(DependencyTypes, dep.Type):
+			// The platform may support types this CLI doesn't know yet
+			v.report(v.nodes[path+".type"], path+".type", SeverityWarning, closest(dep.Type, DependencyTypes),
+				"unknown dependency type '%s', supported types are %s", dep.Type, strings.Join(DependencyTypes, ", "))
+		}
+		v.checkQuantity(path+".storage", dep.Storage)
+	}
+
+	for i, env := range cfg.Env {
+		if env.Name == "" {
+			v.missing(fmt.Sprintf("env[%d].name", i))
+		}
+	}
+
+	for i, volume := range cfg.Volumes {
+		path := fmt.Sprintf("volumes[%d]", i)
+		if volume.Name == "" {
+			v.missing(path + ".name")
+		}
+		if volume.MountPath == "" {
+			v.missing(path + ".mountPath")
+		} else if !strings.HasPrefix(volume.MountPath, "/") {
+			v.add(v.nodes[path+".mountPath"], path+".mountPath", "must be an absolute path")
+		}
+		v.checkQuantity(path+".size", volume.Size)
+	}
+}
+
+// missing reports a required field, at the mapping that lacks it
+func (v *validator) missing(path string) {
+	parent := ""
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		parent = path[:i]
+	}
+	node := v.nodes[parent]
+	for node == nil && parent != "" {
+		if i := strings.LastIndex(parent, "."); i >= 0 {
+			parent = parent[:i]
+		} else {
+			parent = ""
+		}
+		node = v.nodes[parent]
+	}
+	v.add(node, path, "is required")
+}
+
+func (v *validator) checkPort(path string, port int) {
+	if node := v.nodes[path]; node != nil && (port < 1 || port > 65535) {
+		v.add(node, path, "must be between 1 and 65535, got %d", port)
+	}
+}
+
+func (v *validator) checkQuantity(path, value string) {
+	if value != "" && !quantityPattern.MatchString(value) {
+		v.add(v.nodes[path], path, "'%s' is not a resource quantity like 500m, 512Mi or 2Gi", value)
+	}
+}
+
+// withoutDuplicateKeys copies node, keeping the first of duplicate keys
+func withoutDuplicateKeys(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = nil
+
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			copied.Content = append(copied.Content, withoutDuplicateKeys(child))
+		}
+		return &copied
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if seen[key.Value] {
+			continue
+		}
+		seen[key.Value] = true
+		copied.Content = append(copied.Content, key, withoutDuplicateKeys(value))
+	}
+	return &copied
+}
+
+// yamlFields maps the yaml keys of a struct to the types of its fields
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("'%s'", node.Value)
+	}
+}
+
+// suggestName turns an invalid name into a valid one, empty when it can't
+func suggestName(name string) string {
+	name = strings.ToLower(name)
+	name = nameSeparators.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	if name == "" || !DeploymentNamePattern.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// closest returns the candidate nearest to s, empty when none is close
+// enough to be a typo
+func closest(s string, candidates []string) string {
+	best, bestDistance := "", 0
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(s), strings.ToLower(candidate))
+		if best == "" || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" || bestDistance > max(2, len(s)/3) {
+		return ""
+	}
+	return best
+}
+
+// levenshtein is the edit distance between a and b
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
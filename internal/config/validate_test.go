@@ -0,0 +1,181 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validConfig = `name: shop
+container:
+  image: acme/shop:1.0
+  port: 8080
+resources:
+  cpu: 500m
+  memory: 512Mi
+  replicas: 2
+dependencies:
+  - name: db
+    type: postgresql
+    version: "15"
+    storage: 10Gi
+`
+
+// diagnostic is the part of a Diagnostic the tests compare
+type diagnostic struct {
+	line       int
+	path       string
+	severity   Severity
+	message    string
+	suggestion string
+}
+
+func TestValidateDeploymentConfigFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []diagnostic
+	}{
+		{name: "valid", data: validConfig},
+		{
+			name: "unknown field with suggestion",
+			data: "name: shop\ncontainer:\n  image: acme/shop\n  prot: 8080\n",
+			want: []diagnostic{{4, "container.prot", SeverityError, "unknown field", "port"}},
+		},
+		{
+			name: "duplicate key",
+			data: "name: shop\nname: other\ncontainer:\n  image: acme/shop\n",
+			want: []diagnostic{{2, "name", SeverityError, "duplicate key", ""}},
+		},
+		{
+			name: "wrong type skips the value checks",
+			data: "name: shop\ncontainer:\n  image: acme/shop\n  port: http\n",
+			want: []diagnostic{{4, "container.port", SeverityError, "must be a whole number, got 'http'", ""}},
+		},
+		{
+			name: "invalid name with suggestion",
+			data: "name: Shop_App\ncontainer:\n  image: acme/shop\n",
+			want: []diagnostic{{1, "name", SeverityError, "must be at most 63 lowercase letters, digits and '-', starting and ending with a letter or digit", "shop-app"}},
+		},
+		{
+			name: "missing required fields at their parent",
+			data: "name: shop\ncontainer:\n  port: 8080\n",
+			want: []diagnostic{{3, "container.image", SeverityError, "is required", ""}},
+		},
+		{
+			name: "port out of range",
+			data: "name: shop\ncontainer:\n  image: acme/shop\n  port: 70000\n",
+			want: []diagnostic{{4, "container.port", SeverityError, "must be between 1 and 65535, got 70000", ""}},
+		},
+		{
+			name: "resource quantity",
+			data: "name: shop\ncontainer:\n  image: acme/shop\nresources:\n  memory: 512MB\n",
+			want: []diagnostic{{5, "resources.memory", SeverityError, "'512MB' is not a resource quantity like 500m, 512Mi or 2Gi", ""}},
+		},
+		{
+			name: "unknown dependency type is a warning",
+			data: "name: shop\ncontainer:\n  image: acme/shop\ndependencies:\n  - name: db\n    type: postgres\n",
+			want: []diagnostic{{6, "dependencies[0].type", SeverityWarning, "unknown dependency type 'postgres', supported types are " + strings.Join(DependencyTypes, ", "), "postgresql"}},
+		},
+		{
+			name: "mount path",
+			data: "name: shop\ncontainer:\n  image: acme/shop\nvolumes:\n  - name: data\n    mountPath: data\n",
+			want: []diagnostic{{6, "volumes[0].mountPath", SeverityError, "must be an absolute path", ""}},
+		},
+		{
+			name: "list expected",
+			data: "name: shop\ncontainer:\n  image: acme/shop\nenv:\n  LOG_LEVEL: debug\n",
+			want: []diagnostic{{5, "env", SeverityError, "must be a list, got a mapping", ""}},
+		},
+		{
+			name: "empty file",
+			data: "",
+			want: []diagnostic{{0, "", SeverityError, "the file is empty", ""}},
+		},
+		{
+			name: "syntax error",
+			data: "name: shop\ncontainer:\n  image: acme/shop\n  port: 80: 80\n",
+			want: []diagnostic{{4, "", SeverityError, "mapping values are not allowed in this context", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "deployaja.yaml")
+			writeFile(t, file, tt.data)
+			diags, err := ValidateDeploymentConfigFile(file, LoadOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []diagnostic
+			for _, d := range diags {
+				if d.File != file {
+					t.Errorf("diagnostic %v names file %q", d, d.File)
+				}
+				got = append(got, diagnostic{d.Line, d.Path, d.Severity, d.Message, d.Suggestion})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d diagnostics %+v, want %+v", len(got), got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("diagnostic %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDeploymentConfigFileNamesTheOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "deployaja.yaml")
+	overlay := OverlayFile(base, "staging")
+	writeFile(t, base, validConfig)
+	writeFile(t, overlay, "resources:\n  replicas: -1\n")
+
+	diags, err := ValidateDeploymentConfigFile(base, LoadOptions{Env: "staging"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("got %v, want one diagnostic", diags)
+	}
+	if d := diags[0]; d.File != overlay || d.Line != 2 || d.Path != "resources.replicas" {
+		t.Errorf("diagnostic = %v, want resources.replicas at %s:2", d, overlay)
+	}
+}
+
+func TestSuggestName(t *testing.T) {
+	for name, want := range map[string]string{
+		"My App":      "my-app",
+		"shop_api_v2": "shop-api-v2",
+		"--shop--":    "shop",
+		"!!!":         "",
+	} {
+		if got := suggestName(name); got != want {
+			t.Errorf("suggestName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"name", "container", "resources", "healthCheck"}
+	for s, want := range map[string]string{
+		"nme":         "name",
+		"resource":    "resources",
+		"healthcheck": "healthCheck",
+		"volumes":     "",
+	} {
+		if got := closest(s, candidates); got != want {
+			t.Errorf("closest(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,311 @@
+package conformance
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// Call exercises one APIClient method against the stub server
+type Call struct {
+	Method string
+	// Run calls the method, dir is a scratch directory for input files
+	Run func(ctx context.Context, c *api.APIClient, dir string) error
+	// Response is the type the client decodes the success response, or the
+	// events of a stream, into. nil when the body is ignored.
+	Response interface{}
+}
+
+// Calls covers every APIClient method that talks to the platform. Adding a
+// method to the client without adding it here, or to Local, fails the check.
+var Calls = []Call{
+	// Authentication
+	{
+		Method: "CheckAuth",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.CheckAuth(ctx, "3f0b7e8a-1c2d-4e5f-8a9b-0c1d2e3f4a5b")
+			return err
+		},
+		Response: api.AuthCheckResponse{},
+	},
+	{
+		Method: "RequestDeviceCode",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.RequestDeviceCode(ctx)
+			return err
+		},
+		Response: api.DeviceCodeResponse{},
+	},
+	{
+		Method: "WaitForDeviceToken",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.WaitForDeviceToken(ctx, &api.DeviceCodeResponse{DeviceCode: "device-code", ExpiresIn: 60, Interval: 1})
+			return err
+		},
+		Response: api.DeviceTokenResponse{},
+	},
+	{
+		Method:   "RefreshToken",
+		Run:      func(ctx context.Context, c *api.APIClient, dir string) error { return c.RefreshToken(ctx) },
+		Response: api.RefreshResponse{},
+	},
+	{
+		Method: "RevokeToken",
+		Run:    func(ctx context.Context, c *api.APIClient, dir string) error { return c.RevokeToken(ctx) },
+	},
+	{
+		Method: "CreateAPIKey",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.CreateAPIKey(ctx, api.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"deploy"}, ExpiresIn: 3600})
+			return err
+		},
+		Response: api.CreateAPIKeyResponse{},
+	},
+	{
+		Method: "ListAPIKeys",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.ListAPIKeys(ctx)
+			return err
+		},
+		Response: api.APIKeysResponse{},
+	},
+	{
+		Method: "RevokeAPIKey",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			return c.RevokeAPIKey(ctx, "key_3f9a1c")
+		},
+	},
+
+	// Deployments
+	{
+		Method: "GetCostEstimate",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetCostEstimate(ctx, sampleConfig())
+			return err
+		},
+		Response: api.CostResponse{},
+	},
+	{
+		Method: "Validate",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.Validate(ctx, sampleConfig())
+			return err
+		},
+		Response: api.ValidateResponse{},
+	},
+	{
+		Method: "Deploy",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.Deploy(ctx, sampleConfig(), false, "user", "secret", "ghcr.io")
+			return err
+		},
+		Response: api.DeployResponse{},
+	},
+	{
+		Method: "GetStatus",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetStatus(ctx)
+			return err
+		},
+		Response: api.StatusResponse{},
+	},
+	{
+		Method: "ListDeployments",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.ListDeployments(ctx)
+			return err
+		},
+		Response: api.StatusResponse{},
+	},
+	{
+		Method: "GetDeployment",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetDeployment(ctx, "my-app")
+			return err
+		},
+		Response: api.DeploymentStatus{},
+	},
+	{
+		Method: "GetDeploymentStatus",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetDeploymentStatus(ctx, "my-app")
+			return err
+		},
+		Response: api.DeploymentStatus{},
+	},
+	{
+		Method: "WatchDeployment",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			return c.WatchDeployment(ctx, "my-app", func(*api.DeploymentStatus) error { return nil })
+		},
+		Response: api.DeploymentStatus{},
+	},
+	{
+		Method: "PollDeploymentStatus",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.PollDeploymentStatus(ctx, "my-app", api.DefaultWaitOptions(), nil)
+			return err
+		},
+		Response: api.DeploymentStatus{},
+	},
+	{
+		Method: "Describe",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.Describe(ctx, "my-app")
+			return err
+		},
+		Response: api.DescribeResponse{},
+	},
+	{
+		Method: "Restart",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.Restart(ctx, "my-app")
+			return err
+		},
+		Response: api.RestartResponse{},
+	},
+	{
+		Method: "Rollback",
+		Run:    func(ctx context.Context, c *api.APIClient, dir string) error { return c.Rollback(ctx, "my-app") },
+	},
+	{
+		Method: "Drop",
+		Run:    func(ctx context.Context, c *api.APIClient, dir string) error { return c.Drop(ctx, "my-app") },
+	},
+	{
+		Method: "GetLogs",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetLogs(ctx, "my-app", 100, false)
+			return err
+		},
+		Response: api.LogsResponse{},
+	},
+	{
+		Method: "StreamLogs",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			return c.StreamLogs(ctx, "my-app", 100, func(api.LogEntry) error { return nil })
+		},
+		Response: api.LogEntry{},
+	},
+	{
+		Method: "GetLogsStream",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			logChan := make(chan api.LogEntry)
+			errorChan := make(chan error, 1)
+			go c.GetLogsStream(ctx, "my-app", 100, logChan, errorChan)
+			for range logChan {
+			}
+			return <-errorChan
+		},
+		Response: api.LogEntry{},
+	},
+	{
+		Method: "GetEnvVars",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetEnvVars(ctx, "my-app")
+			return err
+		},
+		Response: api.EnvResponse{},
+	},
+	{
+		Method: "UpdateEnvVars",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			return c.UpdateEnvVars(ctx, map[string]string{"LOG_LEVEL": "debug"}, "my-app")
+		},
+	},
+
+	// Dependencies
+	{
+		Method: "GetDependencies",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetDependencies(ctx, "postgresql")
+			return err
+		},
+		Response: api.DependenciesResponse{},
+	},
+	{
+		Method: "GetDependencyInstance",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.GetDependencyInstance(ctx)
+			return err
+		},
+		Response: api.DependencyInstancesResponse{},
+	},
+
+	// Marketplace and generation
+	{
+		Method: "SearchApps",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.SearchApps(ctx, "wordpress")
+			return err
+		},
+		Response: api.SearchResponse{},
+	},
+	{
+		Method: "ListMarketplaceApps",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.ListMarketplaceApps(ctx, map[string]string{
+				"q":         "blog",
+				"category":  "cms",
+				"author":    "deployaja",
+				"sortBy":    "downloads",
+				"sortOrder": "desc",
+				"page":      "1",
+				"limit":     "20",
+			})
+			return err
+		},
+		Response: api.SearchResponse{},
+	},
+	{
+		Method: "InstallApp",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.InstallApp(ctx, "wordpress", "blog.example.com", "blog", true)
+			return err
+		},
+		Response: api.InstallResponse{},
+	},
+	{
+		Method: "PublishApp",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			configPath := filepath.Join(dir, "deployaja.yaml")
+			if err := os.WriteFile(configPath, []byte("name: my-app\n"), 0644); err != nil {
+				return err
+			}
+			_, err := c.PublishApp(ctx, "my-app", "My app", "cms", "me", "1.0.0", "https://github.com/me/my-app", "my-app:1.0.0", []string{"blog"}, configPath)
+			return err
+		},
+		Response: api.AppResponse{},
+	},
+	{
+		Method: "Gen",
+		Run: func(ctx context.Context, c *api.APIClient, dir string) error {
+			_, err := c.Gen(ctx, "node.js api with postgres")
+			return err
+		},
+		Response: api.GenResponse{},
+	},
+}
+
+// Local lists the APIClient methods that never call the platform
+var Local = map[string]string{
+	"HasToken":           "reads the token in memory",
+	"SetToken":           "replaces the token in memory",
+	"IsAPIKey":           "inspects the token type",
+	"IsTokenExpired":     "inspects the JWT exp claim",
+	"GetTokenInfo":       "inspects the JWT claims",
+	"GetBaseURL":         "returns configuration",
+	"GetLoginURL":        "returns configuration",
+	"EnableDebug":        "configures request tracing",
+	"ConfigureTransport": "configures the HTTP transport",
+}
+
+func sampleConfig() *config.DeploymentConfig {
+	cfg := &config.DeploymentConfig{Name: "my-app"}
+	cfg.Container.Image = "nginx:latest"
+	cfg.Container.Port = 80
+	return cfg
+}
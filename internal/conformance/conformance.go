@@ -0,0 +1,262 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/mock"
+)
+
+// callTimeout bounds a single client call, the stub server answers at once
+const callTimeout = 10 * time.Second
+
+// Problem is a difference between the client and the spec
+type Problem struct {
+	Method  string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Method, p.Message)
+}
+
+// Report is the outcome of Check
+type Report struct {
+	Problems []Problem
+	// Unused lists the spec operations no client method calls
+	Unused []string
+}
+
+// Check calls every APIClient method against a stub of spec and reports the
+// requests and response types that don't conform
+func Check(ctx context.Context, spec *Spec) (*Report, error) {
+	dir, err := os.MkdirTemp("", "aja-conformance-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	report := &Report{}
+	add := func(method, format string, args ...interface{}) {
+		report.Problems = append(report.Problems, Problem{Method: method, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Every method must be exercised or declared local
+	called := map[string]bool{}
+	for _, call := range Calls {
+		called[call.Method] = true
+	}
+	clientType := reflect.TypeOf(&api.APIClient{})
+	for i := 0; i < clientType.NumMethod(); i++ {
+		name := clientType.Method(i).Name
+		if !called[name] && Local[name] == "" {
+			add(name, "not covered, add it to conformance.Calls or conformance.Local")
+		}
+	}
+
+	used := map[string]bool{}
+	for _, call := range Calls {
+		if _, exists := clientType.MethodByName(call.Method); !exists {
+			add(call.Method, "no such APIClient method")
+			continue
+		}
+
+		stub := &stub{spec: spec}
+		server := httptest.NewServer(stub)
+
+		client := api.NewApiClientWithURL(server.URL, mock.Token())
+		client.Retry = api.RetryPolicy{}
+		client.TokenStore = nil
+
+		callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+		err := call.Run(callCtx, client, dir)
+		cancel()
+		server.Close()
+
+		for _, problem := range stub.problems {
+			add(call.Method, "%s", problem)
+		}
+		if len(stub.operations) == 0 {
+			if len(stub.problems) == 0 {
+				add(call.Method, "made no request")
+			}
+			continue
+		}
+		if err != nil && len(stub.problems) == 0 {
+			add(call.Method, "failed on the spec's sample response: %v", err)
+		}
+
+		for _, op := range stub.operations {
+			used[op.key] = true
+		}
+
+		// The last request is the one whose response the method returns
+		if call.Response != nil {
+			last := stub.operations[len(stub.operations)-1]
+			schemas := responseSchemas(last.op)
+			if len(schemas) == 0 {
+				add(call.Method, "%s documents no JSON success response or events", last.key)
+				continue
+			}
+			for _, path := range sortedKeys(schemas) {
+				for _, problem := range spec.compare(schemas[path], reflect.TypeOf(call.Response), path) {
+					add(call.Method, "%s %s", last.key, problem)
+				}
+			}
+		}
+	}
+
+	for template, item := range spec.Paths {
+		for method := range item.Operations() {
+			if key := method + " " + template; !used[key] {
+				report.Unused = append(report.Unused, key)
+			}
+		}
+	}
+	sort.Strings(report.Unused)
+
+	return report, nil
+}
+
+// responseSchemas returns the schema of the JSON success response of an
+// operation, or of the events it streams, by the path used in problems
+func responseSchemas(op *Operation) map[string]*Schema {
+	code, resp := op.Success()
+	if resp == nil {
+		return nil
+	}
+
+	schemas := map[string]*Schema{}
+	if media, exists := resp.Content["application/json"]; exists && media.Schema != nil {
+		schemas["response "+code] = media.Schema
+	}
+	for eventType, schema := range resp.Content["text/event-stream"].Events {
+		schemas["event "+eventType] = schema
+	}
+	return schemas
+}
+
+type operation struct {
+	key string
+	op  *Operation
+}
+
+// stub validates requests against the spec and answers them with sample
+// responses built from the success schema
+type stub struct {
+	spec *Spec
+
+	mu         sync.Mutex
+	operations []operation
+	problems   []string
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, s.spec.BasePath())
+	op, template := s.spec.Find(r.Method, path)
+	if op == nil {
+		s.problems = append(s.problems, fmt.Sprintf("%s %s is not in the spec", r.Method, r.URL.Path))
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	key := r.Method + " " + template
+	s.operations = append(s.operations, operation{key: key, op: op})
+	fail := func(format string, args ...interface{}) {
+		s.problems = append(s.problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	// Query parameters
+	declared := map[string]Parameter{}
+	for _, param := range op.Parameters {
+		if param.In == "query" {
+			declared[param.Name] = param
+		}
+	}
+	query := r.URL.Query()
+	for _, name := range sortedKeys(query) {
+		if _, exists := declared[name]; !exists {
+			fail("query parameter %s is not in the spec", name)
+		}
+	}
+	for _, name := range sortedKeys(declared) {
+		if declared[name].Required && !query.Has(name) {
+			fail("required query parameter %s is missing", name)
+		}
+	}
+
+	// Request body
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case op.RequestBody == nil:
+		if len(bytes.TrimSpace(body)) > 0 {
+			fail("sends a request body, the spec has none")
+		}
+	case len(bytes.TrimSpace(body)) == 0:
+		if op.RequestBody.Required {
+			fail("request body is required")
+		}
+	default:
+		media, exists := op.RequestBody.Content["application/json"]
+		if !exists {
+			fail("sends JSON, the spec expects %v", sortedKeys(op.RequestBody.Content))
+			break
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			fail("request body is not JSON: %v", err)
+			break
+		}
+		for _, problem := range s.spec.validate(media.Schema, value, "request") {
+			fail("%s", problem)
+		}
+	}
+
+	// Sample response
+	code, resp := op.Success()
+	status := http.StatusOK
+	fmt.Sscan(code, &status)
+	if resp == nil {
+		fail("documents no success response")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if media, streams := resp.Content["text/event-stream"]; streams {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(status)
+		for _, eventType := range sortedKeys(media.Events) {
+			data, _ := json.Marshal(s.spec.sample(media.Events[eventType]))
+			if eventType != "message" {
+				fmt.Fprintf(w, "event: %s\n", eventType)
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if media, exists := resp.Content["application/json"]; exists {
+		json.NewEncoder(w).Encode(s.spec.sample(media.Schema))
+	}
+}
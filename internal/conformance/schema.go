@@ -0,0 +1,293 @@
+package conformance
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// validate checks a decoded JSON value against a schema, as sent on the wire
+func (s *Spec) validate(schema *Schema, value interface{}, path string) []string {
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	if len(schema.Enum) > 0 && value != nil && !inEnum(schema.Enum, value) {
+		return []string{fmt.Sprintf("%s: %v is not one of %v", path, value, schema.Enum)}
+	}
+
+	switch schema.Type {
+	case "object":
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return []string{fmt.Sprintf("%s: expected object, got %s", path, jsonType(value))}
+		}
+
+		var problems []string
+		for _, name := range schema.Required {
+			if _, exists := object[name]; !exists {
+				problems = append(problems, fmt.Sprintf("%s: required property %s is missing", path, name))
+			}
+		}
+
+		allowed, values := schema.Additional()
+		for _, name := range sortedKeys(object) {
+			property, listed := schema.Properties[name]
+			switch {
+			case listed:
+				problems = append(problems, s.validate(property, object[name], path+"."+name)...)
+			case values != nil:
+				problems = append(problems, s.validate(values, object[name], path+"."+name)...)
+			case !allowed:
+				problems = append(problems, fmt.Sprintf("%s: property %s is not in the spec", path, name))
+			}
+		}
+		return problems
+
+	case "array":
+		array, isArray := value.([]interface{})
+		if !isArray {
+			return []string{fmt.Sprintf("%s: expected array, got %s", path, jsonType(value))}
+		}
+		var problems []string
+		for i, item := range array {
+			problems = append(problems, s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+
+	case "string":
+		str, isString := value.(string)
+		if !isString {
+			return []string{fmt.Sprintf("%s: expected string, got %s", path, jsonType(value))}
+		}
+		if schema.Format == "byte" {
+			if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+				return []string{fmt.Sprintf("%s: expected base64, %v", path, err)}
+			}
+		}
+
+	case "integer":
+		number, isNumber := value.(json.Number)
+		if _, err := number.Int64(); !isNumber || err != nil {
+			return []string{fmt.Sprintf("%s: expected integer, got %s", path, jsonType(value))}
+		}
+
+	case "number":
+		if _, isNumber := value.(json.Number); !isNumber {
+			return []string{fmt.Sprintf("%s: expected number, got %s", path, jsonType(value))}
+		}
+
+	case "boolean":
+		if _, isBool := value.(bool); !isBool {
+			return []string{fmt.Sprintf("%s: expected boolean, got %s", path, jsonType(value))}
+		}
+	}
+
+	return nil
+}
+
+// compare checks that the Go type a response is decoded into matches a
+// schema: every field the client reads must be documented with the same
+// type, and every required property must be read
+func (s *Spec) compare(schema *Schema, t reflect.Type, path string) []string {
+	schema, err := s.resolve(schema)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", path, err)}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if schema == nil || schema.Type == "" || t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	mismatch := func() []string {
+		return []string{fmt.Sprintf("%s: spec type %s doesn't match Go type %s", path, schema.Type, t)}
+	}
+
+	switch schema.Type {
+	case "object":
+		switch t.Kind() {
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return mismatch()
+			}
+			if _, values := schema.Additional(); values != nil {
+				return s.compare(values, t.Elem(), path+"[*]")
+			}
+			return nil
+
+		case reflect.Struct:
+			fields := jsonFields(t)
+			allowed, _ := schema.Additional()
+
+			var problems []string
+			for _, name := range sortedKeys(fields) {
+				property, listed := schema.Properties[name]
+				if !listed {
+					if !allowed {
+						problems = append(problems, fmt.Sprintf("%s: client reads %s, which is not in the spec", path, name))
+					}
+					continue
+				}
+				problems = append(problems, s.compare(property, fields[name], path+"."+name)...)
+			}
+			for _, name := range schema.Required {
+				if _, read := fields[name]; !read {
+					problems = append(problems, fmt.Sprintf("%s: required property %s is not read by the client", path, name))
+				}
+			}
+			return problems
+		}
+		return mismatch()
+
+	case "array":
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return mismatch()
+		}
+		return s.compare(schema.Items, t.Elem(), path+"[]")
+
+	case "string":
+		if t.Kind() != reflect.String {
+			return mismatch()
+		}
+
+	case "integer":
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return mismatch()
+		}
+
+	case "number":
+		if t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64 {
+			return mismatch()
+		}
+
+	case "boolean":
+		if t.Kind() != reflect.Bool {
+			return mismatch()
+		}
+	}
+
+	return nil
+}
+
+// sample builds a response body from a schema, preferring its examples
+func (s *Spec) sample(schema *Schema) interface{} {
+	schema, err := s.resolve(schema)
+	if err != nil || schema == nil {
+		return nil
+	}
+	if schema.Example != nil && schema.Type != "object" && schema.Type != "array" {
+		return schema.Example
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	switch schema.Type {
+	case "object":
+		object := map[string]interface{}{}
+		for name, property := range schema.Properties {
+			object[name] = s.sample(property)
+		}
+		if _, values := schema.Additional(); values != nil && len(schema.Properties) == 0 {
+			object["key"] = s.sample(values)
+		}
+		return object
+	case "array":
+		return []interface{}{s.sample(schema.Items)}
+	case "integer":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return time.Now().UTC().Format(time.RFC3339)
+		case "uri":
+			return "https://example.com"
+		case "byte":
+			return base64.StdEncoding.EncodeToString([]byte("sample"))
+		}
+		return "sample"
+	}
+	return nil
+}
+
+// jsonFields returns the JSON properties a struct decodes, including the
+// ones of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embedded, typ := range jsonFields(field.Type) {
+				fields[embedded] = typ
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
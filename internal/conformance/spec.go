@@ -0,0 +1,267 @@
+// Package conformance checks the API client against openapi.yaml.
+//
+// Every exported APIClient method is called against a stub server built from
+// the spec: requests must hit a documented operation with documented query
+// parameters and a body matching the request schema, and the Go types the
+// client decodes responses into must match the response schemas.
+package conformance
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the subset of an OpenAPI 3 document the checks use
+type Spec struct {
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type PathItem struct {
+	Get    *Operation `yaml:"get"`
+	Put    *Operation `yaml:"put"`
+	Post   *Operation `yaml:"post"`
+	Delete *Operation `yaml:"delete"`
+	Patch  *Operation `yaml:"patch"`
+}
+
+type Operation struct {
+	Summary     string               `yaml:"summary"`
+	Parameters  []Parameter          `yaml:"parameters"`
+	RequestBody *RequestBody         `yaml:"requestBody"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `yaml:"required"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+type Response struct {
+	Description string               `yaml:"description"`
+	Content     map[string]MediaType `yaml:"content"`
+}
+
+// MediaType is the content of a body. Event streams list the schema of the
+// data of each event type in the x-events extension.
+type MediaType struct {
+	Schema *Schema            `yaml:"schema"`
+	Events map[string]*Schema `yaml:"x-events"`
+}
+
+// Schema is a JSON schema object. AdditionalProperties is either a boolean
+// or a schema, see Additional.
+type Schema struct {
+	Ref                  string             `yaml:"$ref"`
+	Type                 string             `yaml:"type"`
+	Format               string             `yaml:"format"`
+	Enum                 []interface{}      `yaml:"enum"`
+	Required             []string           `yaml:"required"`
+	Properties           map[string]*Schema `yaml:"properties"`
+	Items                *Schema            `yaml:"items"`
+	AdditionalProperties *yaml.Node         `yaml:"additionalProperties"`
+	Example              interface{}        `yaml:"example"`
+}
+
+// Load reads an OpenAPI document
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes an OpenAPI document and checks that every $ref resolves
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+
+	var missing []string
+	spec.walkRefs(func(ref string) {
+		if _, err := spec.resolve(&Schema{Ref: ref}); err != nil {
+			missing = append(missing, ref)
+		}
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("unresolved references: %s", strings.Join(missing, ", "))
+	}
+	return &spec, nil
+}
+
+// BasePath is the path prefix of the first server, e.g. /api/v1
+func (s *Spec) BasePath() string {
+	if len(s.Servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(s.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// Operations returns the operations of a path by HTTP method
+func (p *PathItem) Operations() map[string]*Operation {
+	ops := map[string]*Operation{}
+	for method, op := range map[string]*Operation{
+		"GET":    p.Get,
+		"PUT":    p.Put,
+		"POST":   p.Post,
+		"DELETE": p.Delete,
+		"PATCH":  p.Patch,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+// Find returns the operation serving method and path, with the path template
+// it matched. path is relative to BasePath. Templates are tried in order, so
+// /apps/search wins over /apps/{name}.
+func (s *Spec) Find(method, path string) (*Operation, string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, template := range sortedKeys(s.Paths) {
+		item := s.Paths[template]
+		parts := strings.Split(strings.Trim(template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+
+		matches := true
+		for i, part := range parts {
+			if !strings.HasPrefix(part, "{") && part != segments[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			if op := item.Operations()[method]; op != nil {
+				return op, template
+			}
+		}
+	}
+	return nil, ""
+}
+
+// Success returns the lowest 2xx response of an operation
+func (o *Operation) Success() (string, *Response) {
+	var codes []string
+	for code := range o.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return "", nil
+	}
+	sort.Strings(codes)
+	return codes[0], o.Responses[codes[0]]
+}
+
+// resolve follows $ref to a component schema
+func (s *Spec) resolve(schema *Schema) (*Schema, error) {
+	for seen := 0; schema != nil && schema.Ref != ""; seen++ {
+		if seen > 32 {
+			return nil, fmt.Errorf("reference cycle at %s", schema.Ref)
+		}
+		name, found := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if !found {
+			return nil, fmt.Errorf("unsupported reference %s", schema.Ref)
+		}
+		target, exists := s.Components.Schemas[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown schema %s", schema.Ref)
+		}
+		schema = target
+	}
+	return schema, nil
+}
+
+// Additional reports whether an object schema allows properties it doesn't
+// list, and the schema of their values when it gives one
+func (s *Schema) Additional() (bool, *Schema) {
+	if s.AdditionalProperties == nil {
+		// Objects without listed properties are free-form
+		return len(s.Properties) == 0, nil
+	}
+
+	if s.AdditionalProperties.Kind == yaml.ScalarNode {
+		var allowed bool
+		s.AdditionalProperties.Decode(&allowed)
+		return allowed, nil
+	}
+
+	var values Schema
+	if err := s.AdditionalProperties.Decode(&values); err != nil {
+		return true, nil
+	}
+	return true, &values
+}
+
+// walkRefs calls visit for every $ref in the document
+func (s *Spec) walkRefs(visit func(ref string)) {
+	var walk func(*Schema)
+	walk = func(schema *Schema) {
+		if schema == nil {
+			return
+		}
+		if schema.Ref != "" {
+			visit(schema.Ref)
+		}
+		for _, property := range schema.Properties {
+			walk(property)
+		}
+		walk(schema.Items)
+		if _, values := schema.Additional(); values != nil {
+			walk(values)
+		}
+	}
+
+	for _, schema := range s.Components.Schemas {
+		walk(schema)
+	}
+	for _, item := range s.Paths {
+		for _, op := range item.Operations() {
+			for _, param := range op.Parameters {
+				walk(param.Schema)
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					walk(media.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, media := range resp.Content {
+					walk(media.Schema)
+					for _, event := range media.Events {
+						walk(event)
+					}
+				}
+			}
+		}
+	}
+}
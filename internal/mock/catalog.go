@@ -0,0 +1,134 @@
+package mock
+
+import (
+	"encoding/base64"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+)
+
+// catalog returns the marketplace apps a fresh platform starts with
+func catalog() []app {
+	const createdAt = "2025-06-01T00:00:00Z"
+
+	return []app{
+		{
+			MarketplaceApp: api.MarketplaceApp{
+				Name:        "wordpress",
+				Description: "WordPress blog with a managed MySQL database",
+				Category:    "cms",
+				Tags:        []string{"blog", "php", "mysql"},
+				Author:      "deployaja",
+				Version:     "6.5.0",
+				Downloads:   1520,
+				Rating:      4.6,
+				Image:       "wordpress:6.5",
+				Repository:  "https://github.com/WordPress/WordPress",
+			},
+			Config: encodeConfig(`name: wordpress
+container:
+  image: wordpress:6.5
+  port: 80
+resources:
+  cpu: 250m
+  memory: 512Mi
+  replicas: 1
+dependencies:
+  - name: db
+    type: mysql
+    version: "8.0"
+env:
+  - name: WORDPRESS_DB_NAME
+    value: wordpress
+`),
+			CreatedAt: createdAt,
+		},
+		{
+			MarketplaceApp: api.MarketplaceApp{
+				Name:        "ghost",
+				Description: "Ghost publishing platform",
+				Category:    "cms",
+				Tags:        []string{"blog", "node", "mysql"},
+				Author:      "deployaja",
+				Version:     "5.80.0",
+				Downloads:   830,
+				Rating:      4.7,
+				Image:       "ghost:5",
+				Repository:  "https://github.com/TryGhost/Ghost",
+			},
+			Config: encodeConfig(`name: ghost
+container:
+  image: ghost:5
+  port: 2368
+resources:
+  cpu: 250m
+  memory: 512Mi
+  replicas: 1
+dependencies:
+  - name: db
+    type: mysql
+    version: "8.0"
+`),
+			CreatedAt: createdAt,
+		},
+		{
+			MarketplaceApp: api.MarketplaceApp{
+				Name:        "n8n",
+				Description: "Workflow automation with a managed PostgreSQL database",
+				Category:    "automation",
+				Tags:        []string{"workflow", "node", "postgresql"},
+				Author:      "deployaja",
+				Version:     "1.40.0",
+				Downloads:   2210,
+				Rating:      4.8,
+				Image:       "n8nio/n8n:1.40.0",
+				Repository:  "https://github.com/n8n-io/n8n",
+			},
+			Config: encodeConfig(`name: n8n
+container:
+  image: n8nio/n8n:1.40.0
+  port: 5678
+resources:
+  cpu: 500m
+  memory: 1Gi
+  replicas: 1
+dependencies:
+  - name: postgres
+    type: postgresql
+    version: "15"
+`),
+			CreatedAt: createdAt,
+		},
+		{
+			MarketplaceApp: api.MarketplaceApp{
+				Name:        "uptime-kuma",
+				Description: "Self-hosted uptime monitoring",
+				Category:    "monitoring",
+				Tags:        []string{"monitoring", "status-page"},
+				Author:      "deployaja",
+				Version:     "1.23.0",
+				Downloads:   640,
+				Rating:      4.5,
+				Image:       "louislam/uptime-kuma:1",
+				Repository:  "https://github.com/louislam/uptime-kuma",
+			},
+			Config: encodeConfig(`name: uptime-kuma
+container:
+  image: louislam/uptime-kuma:1
+  port: 3001
+resources:
+  cpu: 100m
+  memory: 256Mi
+  replicas: 1
+volumes:
+  - name: data
+    size: 1Gi
+    mountPath: /app/data
+`),
+			CreatedAt: createdAt,
+		},
+	}
+}
+
+func encodeConfig(yamlConfig string) string {
+	return base64.StdEncoding.EncodeToString([]byte(yamlConfig))
+}
@@ -0,0 +1,113 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Route is a canned response for a method and path. Path segments starting
+// with ':' match any value, like Mockoon route parameters.
+type Route struct {
+	Method string
+	Path   string
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// mockoonEnvironment is the subset of a Mockoon environment file we serve
+type mockoonEnvironment struct {
+	EndpointPrefix string `json:"endpointPrefix"`
+	Routes         []struct {
+		Method    string `json:"method"`
+		Endpoint  string `json:"endpoint"`
+		Responses []struct {
+			StatusCode int    `json:"statusCode"`
+			Body       string `json:"body"`
+			Default    bool   `json:"default"`
+			Headers    []struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			} `json:"headers"`
+		} `json:"responses"`
+	} `json:"routes"`
+}
+
+// LoadMockoon reads the routes of a Mockoon environment. Each route serves
+// its default response, or its first one when none is marked default.
+// Mockoon rules and templating are not evaluated, bodies are served as is.
+func LoadMockoon(data []byte) ([]Route, error) {
+	var env mockoonEnvironment
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid Mockoon environment: %v", err)
+	}
+
+	prefix := strings.Trim(env.EndpointPrefix, "/")
+
+	var routes []Route
+	for _, r := range env.Routes {
+		if len(r.Responses) == 0 {
+			continue
+		}
+
+		response := r.Responses[0]
+		for _, candidate := range r.Responses {
+			if candidate.Default {
+				response = candidate
+				break
+			}
+		}
+
+		header := http.Header{}
+		for _, h := range response.Headers {
+			header.Add(h.Key, h.Value)
+		}
+
+		path := strings.Trim(r.Endpoint, "/")
+		if prefix != "" {
+			path = prefix + "/" + path
+		}
+
+		routes = append(routes, Route{
+			Method: strings.ToUpper(r.Method),
+			Path:   "/" + path,
+			Status: response.StatusCode,
+			Header: header,
+			Body:   []byte(response.Body),
+		})
+	}
+
+	return routes, nil
+}
+
+// matches reports whether the route answers a request
+func (r *Route) matches(method, path string) bool {
+	if r.Method != method {
+		return false
+	}
+
+	want := strings.Split(strings.Trim(r.Path, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+
+	for i := range want {
+		if !strings.HasPrefix(want[i], ":") && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// findRoute returns the canned response for a request, nil when there is none
+func findRoute(routes []Route, method, path string) *Route {
+	for i := range routes {
+		if routes[i].matches(method, path) {
+			return &routes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+)
+
+// streamLogs follows a deployment log over SSE. History lines are streamed
+// as they are logged, and between them the app writes a generated request
+// log every LogInterval. Event IDs are "<next history line>:<next generated
+// line>", so a reconnect with Last-Event-ID resumes without repeating lines.
+func (s *Server) streamLogs(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	_, total, resp, found := s.logSince(r, name, 0)
+	if !found {
+		writeJSON(w, resp)
+		return
+	}
+
+	history, generated := total, 0
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		h, g, _ := strings.Cut(lastID, ":")
+		history, _ = strconv.Atoi(h)
+		generated, _ = strconv.Atoi(g)
+	} else if tail, err := strconv.Atoi(r.URL.Query().Get("tail")); err == nil && tail > 0 {
+		history = max(total-tail, 0)
+	}
+
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(s.opts.LogInterval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		lines, total, _, found := s.logSince(r, name, history)
+		if !found {
+			// The deployment was dropped
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return
+		}
+
+		for i, line := range lines {
+			writeLogEvent(w, fmt.Sprintf("%d:%d", total-len(lines)+i+1, generated), line)
+		}
+		history = total
+
+		// The app logs a request every tick
+		if !first {
+			generated++
+			writeLogEvent(w, fmt.Sprintf("%d:%d", history, generated), requestLog(generated))
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// logSince returns the history lines of a deployment from the given line
+// number on, skipping lines trimmed from the history, and the number of
+// lines logged so far
+func (s *Server) logSince(r *http.Request, name string, from int) ([]api.LogEntry, int, response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resp, ok := s.prepare(r, requiresAuth); !ok {
+		return nil, 0, resp, false
+	}
+
+	d, exists := s.state.Deployments[name]
+	if !exists {
+		return nil, 0, notFound(name), false
+	}
+
+	first := d.Lines - len(d.Logs)
+	from = max(from, first)
+	if from >= d.Lines {
+		return nil, d.Lines, response{}, true
+	}
+	return append([]api.LogEntry(nil), d.Logs[from-first:]...), d.Lines, response{}, true
+}
+
+func writeLogEvent(w http.ResponseWriter, id string, entry api.LogEntry) {
+	data, _ := json.Marshal(entry)
+	fmt.Fprintf(w, "id: %s\ndata: %s\n\n", id, data)
+}
+
+// requestLog generates the n-th line of the simulated access log
+func requestLog(n int) api.LogEntry {
+	paths := []string{"/", "/health", "/api/items", "/static/app.js", "/api/items/42"}
+
+	entry := api.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "info",
+		Message:   fmt.Sprintf("GET %s 200 %dms", paths[n%len(paths)], 3+n*7%40),
+		Source:    "app",
+	}
+	if n%7 == 6 {
+		entry.Level = "warn"
+		entry.Message = fmt.Sprintf("Slow response from upstream (%dms)", 800+n%5*100)
+	}
+	return entry
+}
@@ -0,0 +1,977 @@
+// Package mock is an in-process fake of the DeployAja platform for demos,
+// workshops and integration tests that must run offline.
+//
+// The server keeps deployments, environment variables, API keys and
+// marketplace apps in memory, or in a state file shared by several
+// processes, and streams generated logs over SSE. Rollouts advance with
+// time: a deployment is deploying for the rollout delay and running after
+// that, or failed when its image is tagged ':fail'. Routes the fake doesn't
+// simulate are answered from Mockoon fixtures, see LoadMockoon.
+package mock
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRolloutDelay is how long a rollout stays in the deploying state
+	DefaultRolloutDelay = 5 * time.Second
+	// DefaultLogInterval is the pause between generated lines on log streams
+	DefaultLogInterval = 2 * time.Second
+)
+
+// Options configure a Server
+type Options struct {
+	// Routes answer the requests the fake platform doesn't simulate
+	Routes []Route
+	// StatePath persists the state, so processes sharing it see the same
+	// platform. The state is only kept in memory when empty.
+	StatePath string
+	// RolloutDelay is how long rollouts take, zero makes them instant
+	RolloutDelay time.Duration
+	// LogInterval is the pause between generated log lines, DefaultLogInterval when zero
+	LogInterval time.Duration
+}
+
+// Server is the fake platform, serving the API under /api/v1
+type Server struct {
+	opts  Options
+	mux   *http.ServeMux
+	mu    sync.Mutex
+	state *state
+}
+
+// response is the outcome of a request, encoded as JSON
+type response struct {
+	status int
+	header http.Header
+	body   interface{}
+}
+
+type handler func(r *http.Request, st *state) response
+
+type routeFlags int
+
+const (
+	// requiresAuth rejects requests without a valid bearer token
+	requiresAuth routeFlags = 1 << iota
+	// changesState persists the state after successful requests
+	changesState
+)
+
+// NewServer creates a fake platform, loading the state file if there is one
+func NewServer(opts Options) (*Server, error) {
+	if opts.LogInterval <= 0 {
+		opts.LogInterval = DefaultLogInterval
+	}
+
+	s := &Server{
+		opts:  opts,
+		mux:   http.NewServeMux(),
+		state: newState(),
+	}
+	if opts.StatePath != "" {
+		if err := s.state.load(opts.StatePath); err != nil {
+			return nil, err
+		}
+	}
+
+	s.routes()
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	const prefix = "/api/v1"
+
+	s.mux.HandleFunc("GET /login", s.loginPage)
+	s.handle("GET "+prefix+"/check", 0, s.check)
+	s.handle("POST "+prefix+"/auth/device/code", 0, s.deviceCode)
+	s.handle("POST "+prefix+"/auth/device/token", 0, s.deviceToken)
+	s.handle("POST "+prefix+"/auth/refresh", requiresAuth, s.refresh)
+	s.handle("POST "+prefix+"/auth/revoke", requiresAuth|changesState, s.revoke)
+	s.handle("POST "+prefix+"/auth/keys", requiresAuth|changesState, s.createAPIKey)
+	s.handle("GET "+prefix+"/auth/keys", requiresAuth, s.listAPIKeys)
+	s.handle("DELETE "+prefix+"/auth/keys/{id}", requiresAuth|changesState, s.revokeAPIKey)
+
+	s.handle("POST "+prefix+"/validate", 0, s.validate)
+	s.handle("GET "+prefix+"/dependencies", 0, s.dependencies)
+	s.handle("GET "+prefix+"/depInstance", requiresAuth, s.dependencyInstances)
+
+	s.handle("POST "+prefix+"/deploy", requiresAuth|changesState, s.deploy)
+	s.handle("GET "+prefix+"/status", requiresAuth, s.status)
+	s.handle("GET "+prefix+"/list", requiresAuth, s.status)
+	s.handle("GET "+prefix+"/deployments/{name}", requiresAuth, s.deployment)
+	s.mux.HandleFunc("GET "+prefix+"/deployments/{name}/watch", s.watchDeployment)
+	s.handle("GET "+prefix+"/describe/{name}", requiresAuth, s.describe)
+	s.handle("POST "+prefix+"/restart", requiresAuth|changesState, s.restart)
+	s.handle("POST "+prefix+"/rollback", requiresAuth|changesState, s.rollback)
+	s.handle("DELETE "+prefix+"/drop/{name}", requiresAuth|changesState, s.drop)
+	s.handle("GET "+prefix+"/env", requiresAuth, s.getEnv)
+	s.handle("PUT "+prefix+"/env", requiresAuth|changesState, s.setEnv)
+	s.handle("GET "+prefix+"/logs/{name}", requiresAuth, s.logs)
+	s.mux.HandleFunc("GET "+prefix+"/logs/{name}/stream", s.streamLogs)
+
+	s.handle("GET "+prefix+"/search", 0, s.search)
+	s.handle("GET "+prefix+"/marketplace", 0, s.marketplace)
+	s.handle("GET "+prefix+"/install", requiresAuth|changesState, s.install)
+	s.handle("POST "+prefix+"/gen", requiresAuth, s.gen)
+	s.handle("POST "+prefix+"/publish", requiresAuth|changesState, s.publish)
+
+	s.mux.HandleFunc("/", s.fixture)
+}
+
+// handle serves a route with exclusive access to the state
+func (s *Server) handle(pattern string, flags routeFlags, h handler) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		resp, ok := s.prepare(r, flags)
+		if ok {
+			resp = h(r, s.state)
+		}
+
+		if s.opts.StatePath != "" && flags&changesState != 0 && resp.status < 400 {
+			if err := s.state.save(s.opts.StatePath); err != nil {
+				resp = fail(http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+			}
+		}
+
+		writeJSON(w, resp)
+	})
+}
+
+// prepare reloads the state, which another process may have changed since
+// the last request, and checks the token. Callers must hold s.mu.
+func (s *Server) prepare(r *http.Request, flags routeFlags) (response, bool) {
+	if s.opts.StatePath != "" {
+		if err := s.state.load(s.opts.StatePath); err != nil {
+			return fail(http.StatusInternalServerError, "INTERNAL_ERROR", err.Error()), false
+		}
+	}
+	if flags&requiresAuth != 0 && !s.authorized(r, s.state) {
+		return fail(http.StatusUnauthorized, "UNAUTHORIZED", "missing, expired or revoked token"), false
+	}
+	return response{}, true
+}
+
+// authorized accepts any bearer token that wasn't revoked
+func (s *Server) authorized(r *http.Request, st *state) bool {
+	token := bearer(r)
+	return token != "" && !st.isRevoked(token)
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+}
+
+func ok(body interface{}) response {
+	return response{status: http.StatusOK, body: body}
+}
+
+func fail(status int, code, message string) response {
+	body := api.ErrorResponse{Error: api.ErrorBody{Code: code, Message: message}}
+	return response{status: status, body: body}
+}
+
+func notFound(name string) response {
+	return fail(http.StatusNotFound, "DEPLOYMENT_NOT_FOUND", fmt.Sprintf("deployment '%s' not found", name))
+}
+
+func writeJSON(w http.ResponseWriter, resp response) {
+	for key, values := range resp.header {
+		w.Header()[key] = values
+	}
+	if resp.status == http.StatusNotModified {
+		w.WriteHeader(resp.status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	json.NewEncoder(w).Encode(resp.body)
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// Token returns a session token accepted by the fake platform, valid for a day
+func Token() string {
+	now := time.Now()
+	claims, _ := json.Marshal(api.JWTClaims{
+		Subject:   "mock-user",
+		Email:     "dev@deployaja.local",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(24 * time.Hour).Unix(),
+	})
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	return header + "." + base64.RawURLEncoding.EncodeToString(claims) + ".mock"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Authentication
+
+func (s *Server) loginPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<html><body><h1>DeployAja mock platform</h1><p>You are logged in, return to your terminal.</p></body></html>")
+}
+
+// check approves every browser login immediately
+func (s *Server) check(r *http.Request, st *state) response {
+	return ok(api.AuthCheckResponse{Status: "authenticated", Token: Token()})
+}
+
+func (s *Server) deviceCode(r *http.Request, st *state) response {
+	return ok(api.DeviceCodeResponse{
+		DeviceCode:      randomHex(16),
+		UserCode:        "MOCK-CODE",
+		VerificationURI: "http://" + r.Host + "/login",
+		ExpiresIn:       600,
+		Interval:        1,
+	})
+}
+
+// deviceToken approves every device code on the first poll
+func (s *Server) deviceToken(r *http.Request, st *state) response {
+	return ok(api.DeviceTokenResponse{Token: Token()})
+}
+
+func (s *Server) refresh(r *http.Request, st *state) response {
+	return ok(api.RefreshResponse{Token: Token()})
+}
+
+func (s *Server) revoke(r *http.Request, st *state) response {
+	st.Revoked = append(st.Revoked, bearer(r))
+	return ok(map[string]string{"status": "revoked"})
+}
+
+func (s *Server) createAPIKey(r *http.Request, st *state) response {
+	var req api.CreateAPIKeyRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	if req.Name == "" {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", "name is required")
+	}
+
+	now := time.Now().UTC()
+	key := apiKey{
+		APIKey: api.APIKey{
+			ID:        "key_" + randomHex(6),
+			Name:      req.Name,
+			Scopes:    req.Scopes,
+			CreatedAt: now.Format(time.RFC3339),
+		},
+		Key: "aja_mock_" + randomHex(16),
+	}
+	key.Prefix = key.Key[:12]
+	if len(key.Scopes) == 0 {
+		key.Scopes = []string{"*"}
+	}
+	if req.ExpiresIn > 0 {
+		key.ExpiresAt = now.Add(time.Duration(req.ExpiresIn) * time.Second).Format(time.RFC3339)
+	}
+
+	st.APIKeys = append(st.APIKeys, key)
+	return ok(api.CreateAPIKeyResponse{APIKey: key.APIKey, Key: key.Key})
+}
+
+func (s *Server) listAPIKeys(r *http.Request, st *state) response {
+	keys := []api.APIKey{}
+	for _, key := range st.APIKeys {
+		keys = append(keys, key.APIKey)
+	}
+	return ok(api.APIKeysResponse{Keys: keys})
+}
+
+func (s *Server) revokeAPIKey(r *http.Request, st *state) response {
+	id := r.PathValue("id")
+	for i, key := range st.APIKeys {
+		if key.ID == id {
+			st.APIKeys = append(st.APIKeys[:i], st.APIKeys[i+1:]...)
+			st.Revoked = append(st.Revoked, key.Key)
+			return ok(map[string]string{"status": "revoked"})
+		}
+	}
+	return fail(http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("API key '%s' not found", id))
+}
+
+// decodeConfig reads a base64 encoded deployaja.yaml
+func decodeConfig(encoded string) (*config.DeploymentConfig, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("deploymentConfig is not base64: %v", err)
+	}
+
+	var cfg config.DeploymentConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("deploymentConfig is not valid YAML: %v", err)
+	}
+	return &cfg, nil
+}
+
+// validateConfig applies the checks the platform makes before deploying
+func validateConfig(cfg *config.DeploymentConfig) []api.ValidationError {
+	var errs []api.ValidationError
+	add := func(field, code, format string, args ...interface{}) {
+		errs = append(errs, api.ValidationError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case cfg.Name == "":
+		add("name", "REQUIRED_FIELD", "name is required")
+	case len(cfg.Name) > 63 || !config.DeploymentNamePattern.MatchString(cfg.Name):
+		add("name", "INVALID_VALUE", "name must be a lowercase DNS label, got '%s'", cfg.Name)
+	}
+
+	if cfg.Container.Image == "" {
+		add("container.image", "REQUIRED_FIELD", "container.image is required")
+	}
+	if cfg.Container.Port < 0 || cfg.Container.Port > 65535 {
+		add("container.port", "INVALID_VALUE", "container.port must be between 1 and 65535")
+	}
+	if cfg.Resources.Replicas < 0 {
+		add("resources.replicas", "INVALID_VALUE", "resources.replicas must not be negative")
+	}
+
+	for i, dep := range cfg.Dependencies {
+		if dep.Name == "" {
+			add(fmt.Sprintf("dependencies[%d].name", i), "REQUIRED_FIELD", "dependencies[%d].name is required", i)
+		}
+		if !slices.Contains(config.DependencyTypes, dep.Type) {
+			add(fmt.Sprintf("dependencies[%d].type", i), "INVALID_VALUE", "dependencies[%d].type '%s' is not supported", i, dep.Type)
+		}
+	}
+
+	for i, env := range cfg.Env {
+		if env.Name == "" {
+			add(fmt.Sprintf("env[%d].name", i), "REQUIRED_FIELD", "env[%d].name is required", i)
+		}
+	}
+
+	return errs
+}
+
+func validationMessage(errs []api.ValidationError) string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (s *Server) validate(r *http.Request, st *state) response {
+	var req api.ValidateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "INVALID_CONFIG", err.Error())
+	}
+
+	cfg, err := decodeConfig(req.DeploymentConfig)
+	if err != nil {
+		return fail(http.StatusBadRequest, "INVALID_CONFIG", err.Error())
+	}
+
+	if errs := validateConfig(cfg); len(errs) > 0 {
+		body := api.ValidateErrorResponse{
+			Valid:  false,
+			Error:  api.ErrorBody{Code: "VALIDATION_ERROR", Message: validationMessage(errs)},
+			Errors: errs,
+		}
+		return response{status: http.StatusBadRequest, body: body}
+	}
+
+	return ok(api.ValidateResponse{Valid: true, Message: "Configuration is valid"})
+}
+
+// dependencies serves the Mockoon fixture, filtered by type
+func (s *Server) dependencies(r *http.Request, st *state) response {
+	route := findRoute(s.opts.Routes, r.Method, r.URL.Path)
+	if route == nil {
+		return fail(http.StatusNotFound, "NOT_FOUND", "no dependencies fixture loaded")
+	}
+
+	var fixture struct {
+		Dependencies []map[string]interface{} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(route.Body, &fixture); err != nil {
+		return fail(http.StatusInternalServerError, "INTERNAL_ERROR", fmt.Sprintf("invalid dependencies fixture: %v", err))
+	}
+
+	depType := r.URL.Query().Get("type")
+	deps := []map[string]interface{}{}
+	for _, dep := range fixture.Dependencies {
+		if depType == "" || dep["type"] == depType {
+			deps = append(deps, dep)
+		}
+	}
+	return ok(map[string]interface{}{"dependencies": deps})
+}
+
+func (s *Server) dependencyInstances(r *http.Request, st *state) response {
+	instances := []api.DependencyInstanceResponse{}
+	for _, name := range st.names() {
+		d := st.Deployments[name]
+		for _, dep := range d.Config.Dependencies {
+			instances = append(instances, api.DependencyInstanceResponse{
+				ID:     fmt.Sprintf("%s-%s", name, dep.Name),
+				UserID: "mock-user",
+				Type:   dep.Type,
+				Config: map[string]interface{}{
+					"name":       dep.Name,
+					"version":    dep.Version,
+					"deployment": name,
+				},
+				CreatedAt: d.CreatedAt.UTC().Format(time.RFC3339),
+				UpdatedAt: d.DeployedAt.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	return ok(api.DependencyInstancesResponse{Instances: instances})
+}
+
+// Deployments
+
+// apply rolls out cfg, creating the deployment if needed
+func (s *Server) apply(st *state, cfg *config.DeploymentConfig, reason string) *deployment {
+	now := time.Now()
+
+	d, exists := st.Deployments[cfg.Name]
+	if !exists {
+		d = &deployment{CreatedAt: now}
+		st.Deployments[cfg.Name] = d
+	} else {
+		previous := d.Config
+		d.Previous = &previous
+	}
+
+	d.Config = *cfg
+	d.rollout(now, reason)
+	return d
+}
+
+func (s *Server) deploy(r *http.Request, st *state) response {
+	var req api.DeployRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "INVALID_CONFIG", err.Error())
+	}
+
+	cfg, err := decodeConfig(req.DeploymentConfig)
+	if err != nil {
+		return fail(http.StatusBadRequest, "INVALID_CONFIG", err.Error())
+	}
+	if errs := validateConfig(cfg); len(errs) > 0 {
+		return fail(http.StatusBadRequest, "INVALID_CONFIG", validationMessage(errs))
+	}
+
+	if req.DryRun {
+		planned := &deployment{Config: *cfg}
+		return ok(api.DeployResponse{
+			Status:  "validated",
+			Message: "Dry run succeeded, nothing was deployed",
+			URL:     planned.url(),
+		})
+	}
+
+	d := s.apply(st, cfg, "Deployment requested")
+	return ok(api.DeployResponse{
+		DeploymentID:  fmt.Sprintf("dep_%s_%d", cfg.Name, d.Revision),
+		Status:        "deploying",
+		Message:       "Deployment initiated successfully",
+		EstimatedTime: s.opts.RolloutDelay.String(),
+		URL:           d.url(),
+	})
+}
+
+func (s *Server) status(r *http.Request, st *state) response {
+	now := time.Now()
+
+	deployments := []api.DeploymentStatus{}
+	for _, name := range st.names() {
+		deployments = append(deployments, st.Deployments[name].status(now, s.opts.RolloutDelay))
+	}
+	return ok(api.StatusResponse{Deployments: deployments})
+}
+
+func (s *Server) describe(r *http.Request, st *state) response {
+	name := r.PathValue("name")
+	d, exists := st.Deployments[name]
+	if !exists {
+		return notFound(name)
+	}
+
+	status := d.status(time.Now(), s.opts.RolloutDelay)
+	pod := status.Pods[0]
+	started := d.DeployedAt.UTC().Format(time.RFC3339)
+
+	var env []interface{}
+	vars := d.env()
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, map[string]interface{}{"name": k, "value": vars[k]})
+	}
+
+	containerState := map[string]interface{}{"running": map[string]interface{}{"startedAt": started}}
+	if !pod.Ready {
+		containerState = map[string]interface{}{"waiting": map[string]interface{}{"reason": pod.Reason}}
+	}
+
+	container := map[string]interface{}{
+		"name":         d.Config.Name,
+		"image":        d.Config.Container.Image,
+		"ready":        pod.Ready,
+		"restartCount": pod.RestartCount,
+		"state":        containerState,
+		"environment":  env,
+	}
+	if port := d.port(); port > 0 {
+		container["ports"] = []interface{}{map[string]interface{}{"containerPort": port, "protocol": "TCP"}}
+	}
+
+	event := func(eventType, reason, message string, count int) map[string]interface{} {
+		return map[string]interface{}{
+			"type":           eventType,
+			"reason":         reason,
+			"message":        message,
+			"count":          count,
+			"firstTimestamp": started,
+			"lastTimestamp":  started,
+		}
+	}
+	events := []map[string]interface{}{
+		event("Normal", "Scheduled", fmt.Sprintf("Successfully assigned mock/%s to mock-node-1", pod.Name), 1),
+		event("Normal", "Pulled", fmt.Sprintf("Container image \"%s\" already present on machine", d.Config.Container.Image), 1),
+	}
+	switch pod.Status {
+	case "RUNNING":
+		events = append(events, event("Normal", "Started", "Started container "+d.Config.Name, 1))
+	case "CRASH_LOOP":
+		events = append(events, event("Warning", "BackOff", "Back-off restarting failed container", pod.RestartCount))
+	}
+
+	return ok(api.DescribeResponse{
+		Pod: map[string]interface{}{
+			"name":      pod.Name,
+			"namespace": "mock",
+			"nodeName":  "mock-node-1",
+			"phase":     pod.Phase,
+			"podIP":     "10.0.0.10",
+			"hostIP":    "192.168.0.10",
+			"startTime": started,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": strconv.FormatBool(pod.Ready)},
+			},
+			"containers": []interface{}{container},
+		},
+		Events: events,
+	})
+}
+
+func (s *Server) restart(r *http.Request, st *state) response {
+	var req api.RestartRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+
+	d, exists := st.Deployments[req.DeploymentName]
+	if !exists {
+		return notFound(req.DeploymentName)
+	}
+
+	d.rollout(time.Now(), "Restart requested")
+	return ok(api.RestartResponse{
+		Success: true,
+		Data: api.RestartData{
+			Status:  "restarting",
+			Message: fmt.Sprintf("Deployment %s is restarting", req.DeploymentName),
+			Method:  "rollout",
+			RolloutStatus: api.RolloutStatus{
+				Generation:         d.Revision,
+				ObservedGeneration: d.Revision,
+				Replicas:           d.replicas(),
+			},
+		},
+	})
+}
+
+func (s *Server) rollback(r *http.Request, st *state) response {
+	var req api.RollbackRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+
+	d, exists := st.Deployments[req.DeploymentName]
+	if !exists {
+		return notFound(req.DeploymentName)
+	}
+	if d.Previous == nil {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", fmt.Sprintf("deployment '%s' has no previous version", req.DeploymentName))
+	}
+
+	current := d.Config
+	d.Config = *d.Previous
+	d.Previous = &current
+	d.rollout(time.Now(), "Rollback requested")
+
+	return ok(map[string]string{
+		"status":  "rolling_back",
+		"message": "Rollback initiated",
+	})
+}
+
+func (s *Server) drop(r *http.Request, st *state) response {
+	name := r.PathValue("name")
+	if _, exists := st.Deployments[name]; !exists {
+		return notFound(name)
+	}
+
+	delete(st.Deployments, name)
+	return ok(map[string]string{
+		"status":  "deleting",
+		"message": "Deployment deletion initiated",
+	})
+}
+
+// envDeployment resolves the deploymentName query parameter
+func envDeployment(r *http.Request, st *state) (*deployment, response, bool) {
+	name := r.URL.Query().Get("deploymentName")
+	if name == "" {
+		return nil, fail(http.StatusBadRequest, "VALIDATION_ERROR", "deploymentName is required"), false
+	}
+
+	d, exists := st.Deployments[name]
+	if !exists {
+		return nil, notFound(name), false
+	}
+	return d, response{}, true
+}
+
+func (s *Server) getEnv(r *http.Request, st *state) response {
+	d, resp, found := envDeployment(r, st)
+	if !found {
+		return resp
+	}
+	return ok(api.EnvResponse{Variables: d.env()})
+}
+
+// setEnv merges the variables and restarts the pods to pick them up
+func (s *Server) setEnv(r *http.Request, st *state) response {
+	var req api.EnvRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+
+	d, resp, found := envDeployment(r, st)
+	if !found {
+		return resp
+	}
+
+	if d.UserEnv == nil {
+		d.UserEnv = map[string]string{}
+	}
+	for k, v := range req.Variables {
+		d.UserEnv[k] = v
+	}
+	d.rollout(time.Now(), "Environment variables changed")
+
+	return ok(api.EnvResponse{Variables: d.env()})
+}
+
+func (s *Server) logs(r *http.Request, st *state) response {
+	name := r.PathValue("name")
+	d, exists := st.Deployments[name]
+	if !exists {
+		return notFound(name)
+	}
+
+	tail, err := strconv.Atoi(r.URL.Query().Get("tail"))
+	if err != nil || tail <= 0 || tail > len(d.Logs) {
+		tail = len(d.Logs)
+	}
+
+	logs := append([]api.LogEntry{}, d.Logs[len(d.Logs)-tail:]...)
+	return ok(api.LogsResponse{Logs: logs})
+}
+
+// Marketplace
+
+func (s *Server) search(r *http.Request, st *state) response {
+	query := strings.ToLower(r.URL.Query().Get("q"))
+
+	apps := []api.MarketplaceApp{}
+	for _, a := range st.Apps {
+		if matchesApp(a, query) {
+			apps = append(apps, a.MarketplaceApp)
+		}
+	}
+	return ok(api.SearchResponse{Apps: apps, Total: len(apps)})
+}
+
+func matchesApp(a app, query string) bool {
+	if query == "" {
+		return true
+	}
+	if strings.Contains(strings.ToLower(a.Name), query) || strings.Contains(strings.ToLower(a.Description), query) {
+		return true
+	}
+	for _, tag := range a.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) marketplace(r *http.Request, st *state) response {
+	q := r.URL.Query()
+	query := strings.ToLower(q.Get("q"))
+
+	var apps []app
+	for _, a := range st.Apps {
+		if !matchesApp(a, query) {
+			continue
+		}
+		if category := q.Get("category"); category != "" && a.Category != category {
+			continue
+		}
+		if author := q.Get("author"); author != "" && a.Author != author {
+			continue
+		}
+		apps = append(apps, a)
+	}
+
+	less := func(i, j int) bool { return apps[i].Name < apps[j].Name }
+	switch q.Get("sortBy") {
+	case "downloads":
+		less = func(i, j int) bool { return apps[i].Downloads < apps[j].Downloads }
+	case "rating":
+		less = func(i, j int) bool { return apps[i].Rating < apps[j].Rating }
+	case "createdAt", "updatedAt":
+		less = func(i, j int) bool { return apps[i].CreatedAt < apps[j].CreatedAt }
+	}
+	if q.Get("sortOrder") == "desc" {
+		asc := less
+		less = func(i, j int) bool { return asc(j, i) }
+	}
+	sort.SliceStable(apps, less)
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 {
+		limit = 20
+	}
+
+	result := []api.MarketplaceApp{}
+	for i := (page - 1) * limit; i < len(apps) && i < page*limit; i++ {
+		result = append(result, apps[i].MarketplaceApp)
+	}
+	return ok(api.SearchResponse{Apps: result, Total: len(apps)})
+}
+
+func (s *Server) install(r *http.Request, st *state) response {
+	q := r.URL.Query()
+	appName := q.Get("app")
+
+	var found *app
+	for i := range st.Apps {
+		if st.Apps[i].Name == appName {
+			found = &st.Apps[i]
+			break
+		}
+	}
+	if found == nil {
+		return fail(http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("app '%s' not found in the marketplace", appName))
+	}
+
+	cfg, err := decodeConfig(found.Config)
+	if err != nil {
+		return fail(http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	if name := q.Get("name"); name != "" {
+		cfg.Name = name
+	}
+	if domain := q.Get("domain"); domain != "" {
+		cfg.Domain = domain
+	}
+	if errs := validateConfig(cfg); len(errs) > 0 {
+		return fail(http.StatusBadRequest, "INVALID_CONFIG", validationMessage(errs))
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fail(http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+
+	resp := api.InstallResponse{
+		AppName:        appName,
+		DeploymentName: cfg.Name,
+		Config:         base64.StdEncoding.EncodeToString(data),
+	}
+
+	if q.Get("dryRun") == "true" {
+		planned := &deployment{Config: *cfg}
+		resp.Status = "validated"
+		resp.Message = fmt.Sprintf("Dry run: %s %s would be installed as %s", appName, found.Version, cfg.Name)
+		resp.URL = planned.url()
+		return ok(resp)
+	}
+
+	if _, exists := st.Deployments[cfg.Name]; exists {
+		return fail(http.StatusConflict, "CONFLICT", fmt.Sprintf("deployment '%s' already exists, choose another name with --name", cfg.Name))
+	}
+
+	d := s.apply(st, cfg, fmt.Sprintf("Installing %s %s", appName, found.Version))
+	found.Downloads++
+
+	resp.DeploymentID = fmt.Sprintf("dep_%s_%d", cfg.Name, d.Revision)
+	resp.Status = "deploying"
+	resp.Message = fmt.Sprintf("Installing %s %s as %s", appName, found.Version, cfg.Name)
+	resp.EstimatedTime = s.opts.RolloutDelay.String()
+	resp.URL = d.url()
+	return ok(resp)
+}
+
+// gen drafts a configuration, adding the dependencies named in the prompt
+func (s *Server) gen(r *http.Request, st *state) response {
+	var req api.GenRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", "prompt is required")
+	}
+
+	cfg := config.DeploymentConfig{
+		Name:        "my-app",
+		Description: req.Prompt,
+	}
+	cfg.Container.Image = "nginx:latest"
+	cfg.Container.Port = 80
+	cfg.Resources.CPU = "250m"
+	cfg.Resources.Memory = "256Mi"
+	cfg.Resources.Replicas = 1
+
+	prompt := strings.ToLower(req.Prompt)
+	for _, dep := range []struct{ keyword, name, depType, version string }{
+		{"postgres", "postgres", "postgresql", "15"},
+		{"mysql", "mysql", "mysql", "8.0"},
+		{"redis", "redis", "redis", "7"},
+		{"rabbitmq", "rabbitmq", "rabbitmq", "3.12"},
+		{"mongo", "mongodb", "mongodb", "7.0"},
+	} {
+		if strings.Contains(prompt, dep.keyword) {
+			cfg.Dependencies = append(cfg.Dependencies, config.Dependency{Name: dep.name, Type: dep.depType, Version: dep.version})
+		}
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fail(http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return ok(api.GenResponse{Content: string(data)})
+}
+
+func (s *Server) publish(r *http.Request, st *state) response {
+	var req api.PublishRequest
+	if err := decodeBody(r, &req); err != nil {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	if req.Name == "" {
+		return fail(http.StatusBadRequest, "VALIDATION_ERROR", "name is required")
+	}
+	if _, err := decodeConfig(req.Config); err != nil {
+		return fail(http.StatusBadRequest, "INVALID_CONFIG", err.Error())
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	published := app{
+		MarketplaceApp: api.MarketplaceApp{
+			Name:        req.Name,
+			Description: req.Description,
+			Category:    req.Category,
+			Tags:        req.Tags,
+			Author:      req.Author,
+			Version:     req.Version,
+			Image:       req.Image,
+			Repository:  req.Repository,
+		},
+		Config:    req.Config,
+		CreatedAt: now,
+	}
+
+	// Publishing an existing app releases a new version of it
+	replaced := false
+	for i := range st.Apps {
+		if st.Apps[i].Name == req.Name {
+			published.Downloads = st.Apps[i].Downloads
+			st.Apps[i] = published
+			replaced = true
+		}
+	}
+	if !replaced {
+		st.Apps = append(st.Apps, published)
+	}
+
+	return ok(api.AppResponse{
+		ID:          "app_" + req.Name,
+		Name:        req.Name,
+		Message:     fmt.Sprintf("%s %s published to the marketplace", req.Name, req.Version),
+		Status:      "published",
+		PublishedAt: now,
+	})
+}
+
+// fixture answers the remaining routes from the Mockoon environment
+func (s *Server) fixture(w http.ResponseWriter, r *http.Request) {
+	route := findRoute(s.opts.Routes, r.Method, r.URL.Path)
+	if route == nil {
+		writeJSON(w, fail(http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("the mock platform has no route for %s %s", r.Method, r.URL.Path)))
+		return
+	}
+
+	for key, values := range route.Header {
+		w.Header()[key] = values
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(route.Body)
+}
@@ -0,0 +1,290 @@
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// maxLogLines bounds the log history kept per deployment
+const maxLogLines = 1000
+
+// state is everything the fake platform remembers between requests
+type state struct {
+	Deployments map[string]*deployment `json:"deployments"`
+	APIKeys     []apiKey               `json:"apiKeys,omitempty"`
+	Apps        []app                  `json:"apps"`
+	// Revoked holds tokens and API keys that are no longer accepted
+	Revoked []string `json:"revoked,omitempty"`
+}
+
+type deployment struct {
+	Config   config.DeploymentConfig  `json:"config"`
+	Previous *config.DeploymentConfig `json:"previous,omitempty"`
+	// UserEnv holds variables set with PUT /env, they survive redeploys
+	UserEnv map[string]string `json:"userEnv,omitempty"`
+	Logs    []api.LogEntry    `json:"logs"`
+	// Lines counts every line ever logged, Logs holds the last ones
+	Lines      int       `json:"lines"`
+	Revision   int       `json:"revision"`
+	CreatedAt  time.Time `json:"createdAt"`
+	DeployedAt time.Time `json:"deployedAt"`
+}
+
+type apiKey struct {
+	api.APIKey
+	Key string `json:"key"`
+}
+
+// app is a marketplace entry with its base64 encoded deployaja.yaml
+type app struct {
+	api.MarketplaceApp
+	Config    string `json:"config"`
+	CreatedAt string `json:"createdAt"`
+}
+
+func newState() *state {
+	return &state{
+		Deployments: map[string]*deployment{},
+		Apps:        catalog(),
+	}
+}
+
+// load replaces the state with the contents of path, a missing file is an
+// empty platform
+func (s *state) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		*s = *newState()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	loaded := newState()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return fmt.Errorf("invalid mock state %s: %v", path, err)
+	}
+	if loaded.Deployments == nil {
+		loaded.Deployments = map[string]*deployment{}
+	}
+	*s = *loaded
+	return nil
+}
+
+// save atomically writes the state to path
+func (s *state) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *state) isRevoked(token string) bool {
+	for _, revoked := range s.Revoked {
+		if revoked == token {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the deployment names in a stable order
+func (s *state) names() []string {
+	names := make([]string, 0, len(s.Deployments))
+	for name := range s.Deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// log appends lines to the deployment log, dropping the oldest ones
+func (d *deployment) log(now time.Time, level string, format string, args ...interface{}) {
+	d.Logs = append(d.Logs, api.LogEntry{
+		Timestamp: now.UTC().Format(time.RFC3339),
+		Level:     level,
+		Message:   fmt.Sprintf(format, args...),
+		Source:    "app",
+	})
+	d.Lines++
+	if len(d.Logs) > maxLogLines {
+		d.Logs = d.Logs[len(d.Logs)-maxLogLines:]
+	}
+}
+
+// rollout starts a new revision of cfg
+func (d *deployment) rollout(now time.Time, reason string) {
+	d.Revision++
+	d.DeployedAt = now
+	d.log(now, "info", "%s, starting revision %d", reason, d.Revision)
+	d.log(now, "info", "Pulling image %s", d.Config.Container.Image)
+	if d.failing() {
+		d.log(now, "error", "Container exited with code 1")
+		return
+	}
+	d.log(now, "info", "Container started")
+	if port := d.port(); port > 0 {
+		d.log(now, "info", "Listening on port %d", port)
+	}
+}
+
+// failing reports whether the image is meant to crash, images tagged
+// ':fail' simulate a broken release
+func (d *deployment) failing() bool {
+	return strings.HasSuffix(d.Config.Container.Image, ":fail")
+}
+
+func (d *deployment) replicas() int {
+	if d.Config.Resources.Replicas > 0 {
+		return d.Config.Resources.Replicas
+	}
+	return 1
+}
+
+func (d *deployment) port() int {
+	if d.Config.Container.Port > 0 {
+		return d.Config.Container.Port
+	}
+	return 0
+}
+
+func (d *deployment) url() string {
+	if d.Config.Domain != "" {
+		return "https://" + d.Config.Domain
+	}
+	return fmt.Sprintf("https://%s.deployaja.id", d.Config.Name)
+}
+
+// env returns the variables of the running containers: connection strings
+// of the dependencies, the configured env and the ones set with PUT /env
+func (d *deployment) env() map[string]string {
+	vars := map[string]string{}
+
+	for _, dep := range d.Config.Dependencies {
+		prefix := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(dep.Name))
+		host := fmt.Sprintf("%s-%s.deployaja.id", dep.Name, d.Config.Name)
+
+		switch dep.Type {
+		case "postgresql":
+			vars[prefix+"_URL"] = fmt.Sprintf("postgresql://%s:mock@%s:5432/%s", d.Config.Name, host, d.Config.Name)
+		case "mysql":
+			vars[prefix+"_URL"] = fmt.Sprintf("mysql://%s:mock@%s:3306/%s", d.Config.Name, host, d.Config.Name)
+		case "redis":
+			vars[prefix+"_URL"] = fmt.Sprintf("redis://%s:6379", host)
+		case "rabbitmq":
+			vars[prefix+"_URL"] = fmt.Sprintf("amqp://%s:mock@%s:5672", d.Config.Name, host)
+		case "mongodb":
+			vars[prefix+"_URL"] = fmt.Sprintf("mongodb://%s:mock@%s:27017/%s", d.Config.Name, host, d.Config.Name)
+		default:
+			vars[prefix+"_HOST"] = host
+		}
+	}
+
+	for _, v := range d.Config.Env {
+		vars[v.Name] = v.Value
+	}
+	for k, v := range d.Config.EnvMap {
+		vars[k] = v
+	}
+	for k, v := range d.UserEnv {
+		vars[k] = v
+	}
+	return vars
+}
+
+// status derives the rollout state from the time since the last rollout
+func (d *deployment) status(now time.Time, rolloutDelay time.Duration) api.DeploymentStatus {
+	replicas := d.replicas()
+	elapsed := now.Sub(d.DeployedAt)
+
+	status := api.DeploymentStatus{
+		Name:            d.Config.Name,
+		URL:             d.url(),
+		LastDeployed:    d.DeployedAt.UTC().Format(time.RFC3339),
+		CreatedAt:       d.CreatedAt.UTC().Format(time.RFC3339),
+		DesiredReplicas: replicas,
+	}
+
+	pod := api.Pod{
+		Age: elapsed.Round(time.Second).String(),
+		ContainerStatuses: []api.ContainerStatus{{
+			Name: d.Config.Name,
+		}},
+	}
+
+	switch {
+	case elapsed < rolloutDelay:
+		status.Status = "deploying"
+		pod.Phase = "Pending"
+		pod.Status = "PENDING"
+		pod.Reason = "ContainerCreating"
+		pod.ContainerStatuses[0].State = "waiting"
+		pod.ContainerStatuses[0].Reason = "ContainerCreating"
+	case d.failing():
+		// The kubelet backs off restarts, roughly one every 10s here
+		restarts := int((elapsed-rolloutDelay)/(10*time.Second)) + 1
+		status.Status = "failed"
+		pod.Phase = "Running"
+		pod.Status = "CRASH_LOOP"
+		pod.Reason = "CrashLoopBackOff"
+		pod.Message = "back-off restarting failed container"
+		pod.RestartCount = restarts
+		pod.ContainerStatuses[0].State = "waiting"
+		pod.ContainerStatuses[0].Reason = "CrashLoopBackOff"
+		pod.ContainerStatuses[0].RestartCount = restarts
+	default:
+		status.Status = "running"
+		status.AvailableReplicas = replicas
+		status.ReadyReplicas = replicas
+		status.UpdatedReplicas = replicas
+		pod.Phase = "Running"
+		pod.Status = "RUNNING"
+		pod.Ready = true
+		pod.ContainerStatuses[0].Ready = true
+		pod.ContainerStatuses[0].State = "running"
+	}
+
+	for i := 0; i < replicas; i++ {
+		p := pod
+		p.Name = d.podName(i)
+		p.ContainerStatuses = append([]api.ContainerStatus(nil), pod.ContainerStatuses...)
+		status.Pods = append(status.Pods, p)
+	}
+
+	status.Replicas.Desired = replicas
+	status.Replicas.Available = status.AvailableReplicas
+	return status
+}
+
+func (d *deployment) podName(i int) string {
+	return fmt.Sprintf("%s-r%d-%d", d.Config.Name, d.Revision, i)
+}
@@ -0,0 +1,121 @@
+package mock
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+)
+
+const (
+	// watchInterval is how often watch streams look for status changes
+	watchInterval = 500 * time.Millisecond
+	// watchHeartbeat is the pause between keep-alive comments on idle watches
+	watchHeartbeat = 15 * time.Second
+)
+
+// deployment serves a single deployment, answering 304 Not Modified when
+// If-None-Match carries the ETag of its current state
+func (s *Server) deployment(r *http.Request, st *state) response {
+	name := r.PathValue("name")
+	d, exists := st.Deployments[name]
+	if !exists {
+		return notFound(name)
+	}
+
+	status := d.status(time.Now(), s.opts.RolloutDelay)
+	etag := statusETag(status)
+	header := http.Header{"Etag": {etag}}
+
+	if r.Header.Get("If-None-Match") == etag {
+		return response{status: http.StatusNotModified, header: header}
+	}
+	return response{status: http.StatusOK, header: header, body: status}
+}
+
+// watchDeployment streams the state of a deployment over SSE, first the
+// current one and then every change. A dropped deployment ends the stream
+// with a deleted event.
+func (s *Server) watchDeployment(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	status, resp, found := s.currentStatus(r, name)
+	if !found {
+		writeJSON(w, resp)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var sent string
+	lastWrite := time.Now()
+	for {
+		if etag := statusETag(status); etag != sent {
+			data, _ := json.Marshal(status)
+			fmt.Fprintf(w, "id: %s\nevent: status\ndata: %s\n\n", etag, data)
+			sent = etag
+			lastWrite = time.Now()
+		} else if time.Since(lastWrite) >= watchHeartbeat {
+			fmt.Fprint(w, ": ping\n\n")
+			lastWrite = time.Now()
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		status, _, found = s.currentStatus(r, name)
+		if !found {
+			fmt.Fprint(w, "event: deleted\ndata: {}\n\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return
+		}
+	}
+}
+
+// currentStatus returns the state of a deployment now
+func (s *Server) currentStatus(r *http.Request, name string) (api.DeploymentStatus, response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resp, ok := s.prepare(r, requiresAuth); !ok {
+		return api.DeploymentStatus{}, resp, false
+	}
+
+	d, exists := s.state.Deployments[name]
+	if !exists {
+		return api.DeploymentStatus{}, notFound(name), false
+	}
+	return d.status(time.Now(), s.opts.RolloutDelay), response{}, true
+}
+
+// statusETag is a weak ETag of a deployment state. Pod ages change on every
+// read and are left out, so the tag only changes with the rollout.
+func statusETag(status api.DeploymentStatus) string {
+	pods := make([]api.Pod, len(status.Pods))
+	for i, pod := range status.Pods {
+		pod.Age = ""
+		pods[i] = pod
+	}
+	status.Pods = pods
+
+	data, _ := json.Marshal(status)
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf(`W/"%x"`, sum[:8])
+}
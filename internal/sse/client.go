@@ -0,0 +1,241 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRetry is the reconnection delay until the server sends retry:
+	DefaultRetry = time.Second
+	// DefaultMaxRetry caps the backoff between failed reconnections
+	DefaultMaxRetry = 30 * time.Second
+	// DefaultMaxReconnects is how many connections in a row may fail or
+	// end without an event before Subscribe gives up
+	DefaultMaxReconnects = 10
+)
+
+// ErrStop can be returned by a Handler to end the subscription without error
+var ErrStop = errors.New("sse: stop")
+
+// ErrNotEventStream is returned when the server answers with another content
+// type, e.g. a server or proxy that doesn't support streaming
+var ErrNotEventStream = errors.New("sse: response is not an event stream")
+
+// errHeartbeat reports a connection without activity for HeartbeatTimeout
+var errHeartbeat = errors.New("sse: heartbeat timeout")
+
+// Handler is called for every dispatched event. Returning an error ends the
+// subscription with that error; ErrStop ends it cleanly.
+type Handler func(Event) error
+
+// Client subscribes to an event stream, reconnecting when the connection drops
+type Client struct {
+	HTTPClient *http.Client
+
+	// NewRequest builds the request for each connection attempt.
+	// Last-Event-ID, Accept and Cache-Control are set by the client.
+	NewRequest func(ctx context.Context, reconnect bool) (*http.Request, error)
+
+	// CheckResponse converts a non-retryable error response into an error
+	CheckResponse func(*http.Response) error
+
+	// Retry is the initial reconnection delay, DefaultRetry if zero.
+	// A retry: field from the server replaces it.
+	Retry time.Duration
+	// MaxRetry caps the exponential backoff, DefaultMaxRetry if zero
+	MaxRetry time.Duration
+	// MaxReconnects ends the subscription with the last error after this
+	// many connections in a row failed or ended without an event,
+	// DefaultMaxReconnects if zero and unlimited if negative
+	MaxReconnects int
+	// HeartbeatTimeout reconnects when nothing, not even a comment, is
+	// received for this long. Zero disables it.
+	HeartbeatTimeout time.Duration
+
+	// LastEventID is sent on the next connection and updated as events arrive
+	LastEventID string
+}
+
+// Subscribe streams events to handler until ctx is cancelled, the handler
+// returns an error, the server answers 204 No Content or a non-retryable
+// error response, or MaxReconnects connections in a row were unproductive.
+func (c *Client) Subscribe(ctx context.Context, handler Handler) error {
+	retry := c.Retry
+	if retry <= 0 {
+		retry = DefaultRetry
+	}
+	maxRetry := c.MaxRetry
+	if maxRetry <= 0 {
+		maxRetry = DefaultMaxRetry
+	}
+	maxReconnects := c.MaxReconnects
+	if maxReconnects == 0 {
+		maxReconnects = DefaultMaxReconnects
+	}
+
+	failures := 0
+	for attempt := 0; ; attempt++ {
+		received, serverRetry, err := c.connect(ctx, attempt > 0, handler)
+		if errors.Is(err, ErrStop) {
+			return nil
+		}
+		if err != nil && !isReconnectable(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if serverRetry > 0 {
+			retry = serverRetry
+		}
+
+		// Back off on consecutive failures, reconnect promptly after a healthy stream
+		if received {
+			failures = 0
+		} else {
+			failures++
+		}
+		if maxReconnects > 0 && failures >= maxReconnects {
+			return fmt.Errorf("sse: giving up after %d connections without events: %w", failures, err)
+		}
+		delay := retry
+		for i := 1; i < failures && delay < maxRetry; i++ {
+			delay *= 2
+		}
+		if delay > maxRetry {
+			delay = maxRetry
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// reconnectableError marks a failure after which the stream is resumed
+type reconnectableError struct {
+	err error
+}
+
+func (e *reconnectableError) Error() string { return e.err.Error() }
+func (e *reconnectableError) Unwrap() error { return e.err }
+
+func isReconnectable(err error) bool {
+	var r *reconnectableError
+	return errors.As(err, &r)
+}
+
+// connect runs a single connection, reporting whether any event was received
+// and the retry delay requested by the server
+func (c *Client) connect(ctx context.Context, reconnect bool, handler Handler) (bool, time.Duration, error) {
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := c.NewRequest(connCtx, reconnect)
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if c.LastEventID != "" {
+		req.Header.Set("Last-Event-ID", c.LastEventID)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, 0, nil
+		}
+		return false, 0, &reconnectableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		// The server asks the client to stop reconnecting
+		return false, 0, ErrStop
+	case isRetryableStatus(resp.StatusCode):
+		io.Copy(io.Discard, resp.Body)
+		return false, 0, &reconnectableError{err: fmt.Errorf("sse: %s", resp.Status)}
+	case resp.StatusCode != http.StatusOK:
+		if c.CheckResponse != nil {
+			return false, 0, c.CheckResponse(resp)
+		}
+		return false, 0, fmt.Errorf("sse: unexpected status %s", resp.Status)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		return false, 0, fmt.Errorf("%w: content type '%s'", ErrNotEventStream, contentType)
+	}
+
+	var body io.Reader = resp.Body
+	var timedOut atomic.Bool
+	if c.HeartbeatTimeout > 0 {
+		timer := time.AfterFunc(c.HeartbeatTimeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+		body = &activityReader{r: resp.Body, onRead: func() { timer.Reset(c.HeartbeatTimeout) }}
+	}
+
+	decoder := NewDecoder(body)
+	received := false
+	for {
+		event, err := decoder.Next()
+		if err != nil {
+			if timedOut.Load() {
+				err = errHeartbeat
+			}
+			if ctx.Err() != nil {
+				return received, decoder.Retry(), nil
+			}
+			// The stream ended or the connection dropped, resume from LastEventID
+			return received, decoder.Retry(), &reconnectableError{err: err}
+		}
+
+		received = true
+		c.LastEventID = decoder.LastEventID()
+		if err := handler(event); err != nil {
+			return received, decoder.Retry(), err
+		}
+	}
+}
+
+// isRetryableStatus reports responses from overloaded or restarting proxies
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// activityReader calls onRead whenever data arrives
+type activityReader struct {
+	r      io.Reader
+	onRead func()
+}
+
+func (a *activityReader) Read(p []byte) (int, error) {
+	n, err := a.r.Read(p)
+	if n > 0 {
+		a.onRead()
+	}
+	return n, err
+}
@@ -0,0 +1,85 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(server *httptest.Server) *Client {
+	return &Client{
+		HTTPClient: server.Client(),
+		NewRequest: func(ctx context.Context, reconnect bool) (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		},
+		Retry:    time.Millisecond,
+		MaxRetry: 5 * time.Millisecond,
+	}
+}
+
+func TestSubscribeResumesWithLastEventID(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch connections.Add(1) {
+		case 1:
+			fmt.Fprint(w, "id: 7\ndata: before\n\n")
+		default:
+			if got := r.Header.Get("Last-Event-ID"); got != "7" {
+				t.Errorf("Last-Event-ID = %q, want 7", got)
+			}
+			fmt.Fprint(w, "data: after\n\n")
+		}
+	}))
+	defer server.Close()
+
+	var data []string
+	err := newTestClient(server).Subscribe(context.Background(), func(event Event) error {
+		data = append(data, event.Data)
+		if len(data) == 2 {
+			return ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if strings.Join(data, ",") != "before,after" {
+		t.Errorf("events = %v, want before,after", data)
+	}
+}
+
+func TestSubscribeGivesUpOnEmptyStreams(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	client.MaxReconnects = 3
+	err := client.Subscribe(context.Background(), func(Event) error { return nil })
+	if err == nil {
+		t.Fatal("Subscribe returned nil, want an error after 3 empty connections")
+	}
+	if got := connections.Load(); got != 3 {
+		t.Errorf("connections = %d, want 3", got)
+	}
+}
+
+func TestSubscribeStopsOnNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := newTestClient(server).Subscribe(context.Background(), func(Event) error { return nil }); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+}
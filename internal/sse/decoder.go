@@ -0,0 +1,148 @@
+// Package sse implements a Server-Sent Events client following the
+// WHATWG EventSource specification.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEventType is the type of events without an event field
+const DefaultEventType = "message"
+
+// Event is a dispatched server-sent event
+type Event struct {
+	// ID is the last event ID seen on the stream, which persists across events
+	ID string
+	// Type is the event field, or DefaultEventType
+	Type string
+	// Data is the event payload, with multiple data lines joined by "\n"
+	Data string
+}
+
+// Decoder reads events from an event stream
+type Decoder struct {
+	reader *bufio.Reader
+
+	lastEventID string
+	retry       time.Duration
+	started     bool
+	// afterCR is set when the last line ended with "\r", a "\n" right
+	// after it belongs to the same terminator
+	afterCR bool
+}
+
+// NewDecoder returns a decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{reader: bufio.NewReader(r)}
+}
+
+// LastEventID returns the ID to resend as Last-Event-ID on reconnect
+func (d *Decoder) LastEventID() string {
+	return d.lastEventID
+}
+
+// Retry returns the reconnection delay requested by the server, or 0
+func (d *Decoder) Retry() time.Duration {
+	return d.retry
+}
+
+// Next returns the next dispatched event. Events without data are skipped,
+// as required by the specification. At the end of the stream it returns
+// io.EOF and any incomplete event is discarded.
+func (d *Decoder) Next() (Event, error) {
+	var eventType string
+	var data strings.Builder
+	hasData := false
+
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			return Event{}, err
+		}
+
+		// A blank line dispatches the buffered event
+		if line == "" {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			if eventType == "" {
+				eventType = DefaultEventType
+			}
+			return Event{ID: d.lastEventID, Type: eventType, Data: data.String()}, nil
+		}
+
+		// Comments are used as heartbeats
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				d.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				d.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// readLine returns the next line, accepting "\r\n", "\n" and "\r" terminators
+func (d *Decoder) readLine() (string, error) {
+	var line bytes.Buffer
+
+	for {
+		b, err := d.reader.ReadByte()
+		if err != nil {
+			// An unterminated final line is never dispatched
+			return "", err
+		}
+
+		// The "\n" of a "\r\n" is only read with the next line, so a
+		// stream ending its lines with "\r" doesn't wait for more data
+		afterCR := d.afterCR
+		d.afterCR = false
+		if afterCR && b == '\n' && line.Len() == 0 {
+			continue
+		}
+
+		switch b {
+		case '\n':
+			return d.finishLine(&line), nil
+		case '\r':
+			d.afterCR = true
+			return d.finishLine(&line), nil
+		default:
+			line.WriteByte(b)
+		}
+	}
+}
+
+// finishLine strips the UTF-8 byte order mark at the start of the stream
+func (d *Decoder) finishLine(line *bytes.Buffer) string {
+	s := line.String()
+	if !d.started {
+		d.started = true
+		s = strings.TrimPrefix(s, "\uFEFF")
+	}
+	return s
+}
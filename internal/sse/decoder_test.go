@@ -0,0 +1,128 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeAll(t *testing.T, stream string) ([]Event, *Decoder) {
+	t.Helper()
+	decoder := NewDecoder(strings.NewReader(stream))
+	var events []Event
+	for {
+		event, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return events, decoder
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestDecoderLineEndings(t *testing.T) {
+	want := []Event{
+		{Type: "message", Data: "first"},
+		{Type: "update", Data: "second"},
+	}
+	for name, eol := range map[string]string{"LF": "\n", "CR": "\r", "CRLF": "\r\n"} {
+		t.Run(name, func(t *testing.T) {
+			stream := strings.Join([]string{"data: first", "", "event: update", "data: second", "", ""}, eol)
+			events, _ := decodeAll(t, stream)
+			if len(events) != len(want) {
+				t.Fatalf("got %d events %+v, want %d", len(events), events, len(want))
+			}
+			for i := range want {
+				if events[i] != want[i] {
+					t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderMixedLineEndings(t *testing.T) {
+	events, _ := decodeAll(t, "data: a\r\ndata: b\rdata: c\n\r\n")
+	if len(events) != 1 || events[0].Data != "a\nb\nc" {
+		t.Fatalf("got %+v, want a single event with data a\\nb\\nc", events)
+	}
+}
+
+func TestDecoderDispatchesOnCRWithoutWaiting(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	go writer.Write([]byte("data: live\r\r"))
+
+	decoder := NewDecoder(reader)
+	done := make(chan Event, 1)
+	go func() {
+		event, err := decoder.Next()
+		if err == nil {
+			done <- event
+		}
+	}()
+
+	select {
+	case event := <-done:
+		if event.Data != "live" {
+			t.Fatalf("data = %q, want live", event.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event ending with \\r was not dispatched until more data arrived")
+	}
+}
+
+func TestDecoderMultiLineData(t *testing.T) {
+	events, _ := decodeAll(t, "data: line 1\ndata:line 2\ndata\ndata:  indented\n\n")
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if want := "line 1\nline 2\n\n indented"; events[0].Data != want {
+		t.Errorf("data = %q, want %q", events[0].Data, want)
+	}
+}
+
+func TestDecoderSkipsCommentsAndEmptyEvents(t *testing.T) {
+	events, _ := decodeAll(t, ": heartbeat\n\nevent: ping\n\ndata: x\n\n")
+	if len(events) != 1 || events[0].Type != DefaultEventType || events[0].Data != "x" {
+		t.Fatalf("got %+v, want a single message event with data x", events)
+	}
+}
+
+func TestDecoderID(t *testing.T) {
+	events, decoder := decodeAll(t, "id: 1\ndata: a\n\ndata: b\n\nid: bad\x00id\ndata: c\n\nid\ndata: d\n\n")
+	want := []string{"1", "1", "1", ""}
+	for i, id := range want {
+		if events[i].ID != id {
+			t.Errorf("event %d ID = %q, want %q", i, events[i].ID, id)
+		}
+	}
+	if decoder.LastEventID() != "" {
+		t.Errorf("LastEventID = %q, want it reset by the empty id", decoder.LastEventID())
+	}
+}
+
+func TestDecoderRetry(t *testing.T) {
+	_, decoder := decodeAll(t, "retry: 2500\n\nretry: soon\n\nretry: -1\n\n")
+	if decoder.Retry() != 2500*time.Millisecond {
+		t.Errorf("Retry = %s, want 2.5s and invalid values ignored", decoder.Retry())
+	}
+}
+
+func TestDecoderDiscardsIncompleteEvent(t *testing.T) {
+	events, _ := decodeAll(t, "data: complete\n\ndata: cut off\n")
+	if len(events) != 1 || events[0].Data != "complete" {
+		t.Fatalf("got %+v, want only the complete event", events)
+	}
+}
+
+func TestDecoderStripsByteOrderMark(t *testing.T) {
+	events, _ := decodeAll(t, "\uFEFFdata: x\n\n")
+	if len(events) != 1 || events[0].Data != "x" {
+		t.Fatalf("got %+v, want data x", events)
+	}
+}
@@ -128,3 +128,12 @@ func formatSeparator(widths []int) string {
 	result.WriteString("\n")
 	return result.String()
 }
+
+// ProgressBar draws done out of total as a bar of the given width
+func ProgressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(max(done, 0)*width/total, width)
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// IsTerminal reports whether f is attached to an interactive terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// IsTerminalWriter reports whether w is a file attached to an interactive terminal
+func IsTerminalWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && IsTerminal(f)
+}
+
+// LiveRegion redraws a block of lines in place, for progress views on
+// terminals. Lines must fit the terminal width, wrapped lines aren't erased.
+type LiveRegion struct {
+	w     io.Writer
+	lines int
+}
+
+// NewLiveRegion returns a region starting at the current line of w
+func NewLiveRegion(w io.Writer) *LiveRegion {
+	return &LiveRegion{w: w}
+}
+
+// Update replaces the previous content of the region with text
+func (r *LiveRegion) Update(text string) {
+	if r.lines > 0 {
+		// Move to the first line of the region and clear to the end of the screen
+		fmt.Fprintf(r.w, "\x1b[%dA\r\x1b[J", r.lines)
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	fmt.Fprint(r.w, text)
+	r.lines = strings.Count(text, "\n")
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
-	"deployaja-cli/cmd"
+	_ "embed"
+
+	"github.com/deployaja/deployaja-cli/cmd"
 )
 
+// mockoonEnvironment backs 'aja dev-server' and DEPLOYAJA_API_URL=mock://
+//
+//go:embed mockoon.json
+var mockoonEnvironment []byte
+
 func main() {
+	cmd.SetMockEnvironment(mockoonEnvironment)
 	cmd.Execute()
 }
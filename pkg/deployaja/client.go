@@ -0,0 +1,365 @@
+package deployaja
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+)
+
+// Client talks to the DeployAja platform. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	tokens     TokenSource
+	httpClient *http.Client
+	userAgent  string
+	retry      RetryPolicy
+	// cache is shared by the per-call API clients so GetDeployment can
+	// revalidate with If-None-Match across calls
+	cache *api.DeploymentCache
+}
+
+// New creates a client. Without WithToken or WithTokenSource, the token is
+// read from DEPLOYAJA_TOKEN.
+func New(opts ...Option) (*Client, error) {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		tokens:     EnvToken(),
+		httpClient: defaultHTTPClient(),
+		userAgent:  "deployaja-go/" + Version,
+		retry:      DefaultRetryPolicy(),
+		cache:      api.NewDeploymentCache(),
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	c.baseURL = strings.TrimSuffix(c.baseURL, "/")
+	return c, nil
+}
+
+// apiClient returns a client for a single call, authenticated with the current token
+func (c *Client) apiClient(ctx context.Context) (*api.APIClient, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	client := api.NewApiClientWithURL(c.baseURL, token)
+	client.HTTPClient = c.httpClient
+	client.UserAgent = c.userAgent
+	client.Retry = api.RetryPolicy(c.retry)
+	client.Cache = c.cache
+	client.TokenStore = c.updateToken
+	return client, nil
+}
+
+// updateToken lets the token source keep refreshed tokens. Sources that
+// can't store them only offer their current token.
+func (c *Client) updateToken(ctx context.Context, update func(current string) (string, error)) error {
+	if updater, ok := c.tokens.(TokenUpdater); ok {
+		return updater.UpdateToken(ctx, update)
+	}
+
+	current, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = update(current)
+	return err
+}
+
+// Deploy deploys or updates an application
+func (c *Client) Deploy(ctx context.Context, req DeployRequest) (*DeployResponse, error) {
+	if req.Config == nil {
+		return nil, fmt.Errorf("deploy request has no config")
+	}
+
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Deploy(ctx, toConfig(req.Config), req.DryRun, req.Registry.Username, req.Registry.Password, req.Registry.Server)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return (*DeployResponse)(resp), nil
+}
+
+// Validate checks a configuration without deploying it. An invalid
+// configuration is reported with Valid false and a nil error.
+func (c *Client) Validate(ctx context.Context, cfg *DeploymentConfig) (*ValidateResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Validate(ctx, toConfig(cfg))
+	if resp != nil && !resp.Valid {
+		return (*ValidateResponse)(resp), nil
+	}
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return (*ValidateResponse)(resp), nil
+}
+
+// EstimateCost returns the monthly and daily cost of a configuration
+func (c *Client) EstimateCost(ctx context.Context, cfg *DeploymentConfig) (*CostResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.GetCostEstimate(ctx, toConfig(cfg))
+	return fromCost(resp), convertError(err)
+}
+
+// ListDeployments returns all deployments of the account
+func (c *Client) ListDeployments(ctx context.Context) (*StatusResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.GetStatus(ctx)
+	return fromStatus(resp), convertError(err)
+}
+
+// GetDeployment returns a single deployment. A missing deployment matches ErrNotFound.
+func (c *Client) GetDeployment(ctx context.Context, name string) (*DeploymentStatus, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := client.GetDeployment(ctx, name)
+	return fromDeployment(deployment), convertError(err)
+}
+
+// WatchDeployment calls handle with the current state of a deployment and
+// then with every change, until ctx is cancelled or handle returns an error.
+// Returning ErrStopWatch from handle ends the watch without error. A dropped
+// deployment matches ErrNotFound.
+func (c *Client) WatchDeployment(ctx context.Context, name string, handle func(*DeploymentStatus) error) error {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return convertError(client.WatchDeployment(ctx, name, func(deployment *api.DeploymentStatus) error {
+		return handle(fromDeployment(deployment))
+	}))
+}
+
+// WaitForDeployment watches a deployment until it reaches a final state or ctx
+// is done. It returns the final state together with RolloutOutcome, so a
+// failed rollout matches ErrRolloutFailed, and running out of opts.Timeout
+// or a rollout timing out on the platform matches ErrRolloutTimeout.
+func (c *Client) WaitForDeployment(ctx context.Context, name string, opts WaitOptions) (*DeploymentStatus, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	waitOpts := api.WaitOptions{
+		Timeout:             opts.Timeout,
+		PollInterval:        opts.PollInterval,
+		NotFoundGracePeriod: opts.NotFoundGracePeriod,
+	}
+	final, err := client.PollDeploymentStatus(ctx, name, waitOpts, func(deployment *api.DeploymentStatus) error {
+		if opts.OnStatus != nil {
+			opts.OnStatus(fromDeployment(deployment))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, convertError(err)
+	}
+
+	deployment := fromDeployment(final)
+	return deployment, RolloutOutcome(deployment)
+}
+
+// Describe returns pod details and events of a deployment
+func (c *Client) Describe(ctx context.Context, name string) (*DescribeResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Describe(ctx, name)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return (*DescribeResponse)(resp), nil
+}
+
+// Restart recreates the pods of a deployment
+func (c *Client) Restart(ctx context.Context, name string) (*RestartResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Restart(ctx, name)
+	return fromRestart(resp), convertError(err)
+}
+
+// Rollback returns a deployment to its previous version
+func (c *Client) Rollback(ctx context.Context, name string) error {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return convertError(client.Rollback(ctx, name))
+}
+
+// Drop deletes a deployment
+func (c *Client) Drop(ctx context.Context, name string) error {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return convertError(client.Drop(ctx, name))
+}
+
+// Logs returns the last tail log lines of a deployment
+func (c *Client) Logs(ctx context.Context, name string, tail int) ([]LogEntry, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := client.GetLogs(ctx, name, tail, false)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return fromLogs(entries), nil
+}
+
+// StreamLogs calls handle for every log line, starting with the last tail
+// lines, until ctx is cancelled or handle returns an error. Dropped
+// connections are resumed without losing or repeating lines.
+func (c *Client) StreamLogs(ctx context.Context, name string, tail int, handle func(LogEntry) error) error {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return convertError(client.StreamLogs(ctx, name, tail, func(entry api.LogEntry) error {
+		return handle(LogEntry(entry))
+	}))
+}
+
+// GetEnv returns the environment variables of a deployment
+func (c *Client) GetEnv(ctx context.Context, name string) (map[string]string, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := client.GetEnvVars(ctx, name)
+	return vars, convertError(err)
+}
+
+// SetEnv sets environment variables of a deployment, leaving others unchanged
+func (c *Client) SetEnv(ctx context.Context, name string, vars map[string]string) error {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return convertError(client.UpdateEnvVars(ctx, vars, name))
+}
+
+// Dependencies lists the managed dependencies, optionally of one type
+func (c *Client) Dependencies(ctx context.Context, depType string) (*DependenciesResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.GetDependencies(ctx, depType)
+	return fromDependencies(resp), convertError(err)
+}
+
+// SearchApps searches the marketplace
+func (c *Client) SearchApps(ctx context.Context, query string) (*SearchResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.SearchApps(ctx, query)
+	return fromSearch(resp), convertError(err)
+}
+
+// ListApps lists marketplace apps
+func (c *Client) ListApps(ctx context.Context, req ListAppsRequest) (*SearchResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{}
+	for key, value := range map[string]string{
+		"q":         req.Query,
+		"category":  req.Category,
+		"author":    req.Author,
+		"sortBy":    req.SortBy,
+		"sortOrder": req.SortOrder,
+	} {
+		if value != "" {
+			params[key] = value
+		}
+	}
+	if req.Page > 0 {
+		params["page"] = strconv.Itoa(req.Page)
+	}
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	resp, err := client.ListMarketplaceApps(ctx, params)
+	return fromSearch(resp), convertError(err)
+}
+
+// InstallApp deploys an app from the marketplace
+func (c *Client) InstallApp(ctx context.Context, req InstallRequest) (*InstallResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.InstallApp(ctx, req.App, req.Domain, req.Name, req.DryRun)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return (*InstallResponse)(resp), nil
+}
+
+// CreateAPIKey creates a service-account API key. The key is only returned once.
+func (c *Client) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.CreateAPIKey(ctx, api.CreateAPIKeyRequest(req))
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return &CreateAPIKeyResponse{APIKey: APIKey(resp.APIKey), Key: resp.Key}, nil
+}
+
+// ListAPIKeys lists the service-account API keys of the account
+func (c *Client) ListAPIKeys(ctx context.Context) (*APIKeysResponse, error) {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.ListAPIKeys(ctx)
+	return fromAPIKeys(resp), convertError(err)
+}
+
+// RevokeAPIKey revokes a service-account API key
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	client, err := c.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return convertError(client.RevokeAPIKey(ctx, id))
+}
@@ -0,0 +1,84 @@
+package deployaja
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(WithBaseURL(server.URL), WithToken("token"), WithRetryPolicy(RetryPolicy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestWaitForDeploymentReportsFailedRollout(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/deployments/shop" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"name":"shop","status":"failed","pods":[{"name":"shop-1","restartCount":4}]}`)
+	})
+
+	var updates int
+	status, err := client.WaitForDeployment(context.Background(), "shop", WaitOptions{
+		Timeout:      time.Second,
+		PollInterval: time.Millisecond,
+		OnStatus:     func(*DeploymentStatus) { updates++ },
+	})
+	if !errors.Is(err, ErrRolloutFailed) || errors.Is(err, ErrRolloutTimeout) {
+		t.Fatalf("err = %v, want ErrRolloutFailed", err)
+	}
+	if status == nil || status.Status != "failed" || status.Pods[0].RestartCount != 4 {
+		t.Errorf("status = %+v, want the failed deployment with its pods", status)
+	}
+	if updates == 0 {
+		t.Error("OnStatus was not called")
+	}
+}
+
+func TestWaitForDeploymentTimesOut(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/deployments/shop" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"name":"shop","status":"deploying"}`)
+	})
+
+	_, err := client.WaitForDeployment(context.Background(), "shop", WaitOptions{
+		Timeout:      50 * time.Millisecond,
+		PollInterval: time.Millisecond,
+	})
+	if !errors.Is(err, ErrRolloutTimeout) {
+		t.Fatalf("err = %v, want ErrRolloutTimeout", err)
+	}
+}
+
+func TestAPIErrorsAreSDKErrors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":"NOT_FOUND","message":"deployment not found"}}`)
+	})
+
+	_, err := client.GetDeployment(context.Background(), "shop")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %T %v, want an *Error", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "NOT_FOUND" || !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %+v, want a NOT_FOUND error matching ErrNotFound", apiErr)
+	}
+}
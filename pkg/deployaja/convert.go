@@ -0,0 +1,224 @@
+package deployaja
+
+import (
+	"errors"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// The SDK owns its types so the internal packages can change without
+// breaking callers. These functions convert at the boundary.
+
+func toConfig(cfg *DeploymentConfig) *config.DeploymentConfig {
+	if cfg == nil {
+		return nil
+	}
+
+	out := &config.DeploymentConfig{
+		Name:        cfg.Name,
+		Description: cfg.Description,
+		Domain:      cfg.Domain,
+		EnvMap:      cfg.EnvMap,
+	}
+	out.Container.Image = cfg.Container.Image
+	out.Container.Port = cfg.Container.Port
+	out.Resources.CPU = cfg.Resources.CPU
+	out.Resources.Memory = cfg.Resources.Memory
+	out.Resources.Replicas = cfg.Resources.Replicas
+	out.HealthCheck.Path = cfg.HealthCheck.Path
+	out.HealthCheck.Port = cfg.HealthCheck.Port
+	out.HealthCheck.InitialDelaySeconds = cfg.HealthCheck.InitialDelaySeconds
+	out.HealthCheck.PeriodSeconds = cfg.HealthCheck.PeriodSeconds
+
+	for _, dep := range cfg.Dependencies {
+		out.Dependencies = append(out.Dependencies, config.Dependency(dep))
+	}
+	for _, env := range cfg.Env {
+		out.Env = append(out.Env, config.EnvVar(env))
+	}
+	for _, volume := range cfg.Volumes {
+		out.Volumes = append(out.Volumes, config.Volume(volume))
+	}
+	if cfg.DockerConfig != nil {
+		out.DockerConfig = &config.DockerConfig{Auths: map[string]config.DockerAuth{}}
+		for registry, auth := range cfg.DockerConfig.Auths {
+			out.DockerConfig.Auths[registry] = config.DockerAuth(auth)
+		}
+	}
+	return out
+}
+
+func fromConfig(cfg *config.DeploymentConfig) *DeploymentConfig {
+	if cfg == nil {
+		return nil
+	}
+
+	out := &DeploymentConfig{
+		Name:        cfg.Name,
+		Description: cfg.Description,
+		Container:   Container(cfg.Container),
+		Resources:   Resources(cfg.Resources),
+		HealthCheck: HealthCheck(cfg.HealthCheck),
+		Domain:      cfg.Domain,
+		EnvMap:      cfg.EnvMap,
+	}
+
+	for _, dep := range cfg.Dependencies {
+		out.Dependencies = append(out.Dependencies, Dependency(dep))
+	}
+	for _, env := range cfg.Env {
+		out.Env = append(out.Env, EnvVar(env))
+	}
+	for _, volume := range cfg.Volumes {
+		out.Volumes = append(out.Volumes, Volume(volume))
+	}
+	if cfg.DockerConfig != nil {
+		out.DockerConfig = &DockerConfig{Auths: map[string]DockerAuth{}}
+		for registry, auth := range cfg.DockerConfig.Auths {
+			out.DockerConfig.Auths[registry] = DockerAuth(auth)
+		}
+	}
+	return out
+}
+
+func fromDeployment(d *api.DeploymentStatus) *DeploymentStatus {
+	if d == nil {
+		return nil
+	}
+
+	out := &DeploymentStatus{
+		Name:              d.Name,
+		Status:            d.Status,
+		URL:               d.URL,
+		LastDeployed:      d.LastDeployed,
+		CreatedAt:         d.CreatedAt,
+		DesiredReplicas:   d.DesiredReplicas,
+		AvailableReplicas: d.AvailableReplicas,
+		ReadyReplicas:     d.ReadyReplicas,
+		UpdatedReplicas:   d.UpdatedReplicas,
+	}
+	for _, pod := range d.Pods {
+		converted := Pod{
+			Name:         pod.Name,
+			Phase:        pod.Phase,
+			Ready:        pod.Ready,
+			RestartCount: pod.RestartCount,
+			Age:          pod.Age,
+			Status:       pod.Status,
+			Reason:       pod.Reason,
+			Message:      pod.Message,
+		}
+		for _, status := range pod.ContainerStatuses {
+			converted.ContainerStatuses = append(converted.ContainerStatuses, ContainerStatus(status))
+		}
+		out.Pods = append(out.Pods, converted)
+	}
+	return out
+}
+
+func fromStatus(resp *api.StatusResponse) *StatusResponse {
+	if resp == nil {
+		return nil
+	}
+
+	out := &StatusResponse{Deployments: []DeploymentStatus{}}
+	for i := range resp.Deployments {
+		out.Deployments = append(out.Deployments, *fromDeployment(&resp.Deployments[i]))
+	}
+	return out
+}
+
+func fromCost(resp *api.CostResponse) *CostResponse {
+	if resp == nil {
+		return nil
+	}
+	return &CostResponse{
+		EstimatedCost: Cost(resp.EstimatedCost),
+		Breakdown:     CostBreakdown(resp.Breakdown),
+	}
+}
+
+func fromRestart(resp *api.RestartResponse) *RestartResponse {
+	if resp == nil {
+		return nil
+	}
+	return &RestartResponse{
+		Success: resp.Success,
+		Data: RestartData{
+			Status:        resp.Data.Status,
+			Message:       resp.Data.Message,
+			Method:        resp.Data.Method,
+			RolloutStatus: RolloutStatus(resp.Data.RolloutStatus),
+		},
+	}
+}
+
+func fromDependencies(resp *api.DependenciesResponse) *DependenciesResponse {
+	if resp == nil {
+		return nil
+	}
+
+	out := &DependenciesResponse{Dependencies: []DependencyInfo{}}
+	for _, dep := range resp.Dependencies {
+		out.Dependencies = append(out.Dependencies, DependencyInfo{
+			Type:           dep.Type,
+			Name:           dep.Name,
+			Versions:       dep.Versions,
+			DefaultVersion: dep.DefaultVersion,
+			Pricing:        DependencyPricing(dep.Pricing),
+			Specs:          DependencySpecs(dep.Specs),
+		})
+	}
+	return out
+}
+
+func fromSearch(resp *api.SearchResponse) *SearchResponse {
+	if resp == nil {
+		return nil
+	}
+
+	out := &SearchResponse{Apps: []MarketplaceApp{}, Total: resp.Total}
+	for _, app := range resp.Apps {
+		out.Apps = append(out.Apps, MarketplaceApp(app))
+	}
+	return out
+}
+
+func fromAPIKeys(resp *api.APIKeysResponse) *APIKeysResponse {
+	if resp == nil {
+		return nil
+	}
+
+	out := &APIKeysResponse{Keys: []APIKey{}}
+	for _, key := range resp.Keys {
+		out.Keys = append(out.Keys, APIKey(key))
+	}
+	return out
+}
+
+func fromLogs(entries []api.LogEntry) []LogEntry {
+	out := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, LogEntry(entry))
+	}
+	return out
+}
+
+// convertError replaces an API error in the chain of err with an *Error,
+// keeping the message of the whole chain
+func convertError(err error) error {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	return &Error{
+		StatusCode: apiErr.StatusCode,
+		Code:       apiErr.Code,
+		Message:    apiErr.Message,
+		Details:    apiErr.Details,
+		RequestID:  apiErr.RequestID,
+		msg:        err.Error(),
+		cause:      apiErr,
+	}
+}
@@ -0,0 +1,34 @@
+// Package deployaja is the Go SDK for the DeployAja platform.
+//
+// It uses the same client as the aja CLI, so requests are retried on
+// transient failures with the same backoff, mutating calls send an
+// Idempotency-Key, expired session tokens are refreshed, and failures are
+// reported as *Error values that match the Err* sentinels with errors.Is.
+//
+//	client, err := deployaja.New(deployaja.WithToken(os.Getenv("DEPLOYAJA_TOKEN")))
+//	if err != nil {
+//		return err
+//	}
+//
+//	cfg, err := deployaja.LoadConfig("deployaja.yaml")
+//	if err != nil {
+//		return err
+//	}
+//
+//	if _, err := client.Deploy(ctx, deployaja.DeployRequest{Config: cfg}); err != nil {
+//		return err
+//	}
+//	status, err := client.WaitForDeployment(ctx, cfg.Name, deployaja.WaitOptions{Timeout: 5 * time.Minute})
+//	if errors.Is(err, deployaja.ErrRolloutFailed) {
+//		// the rollout failed, status holds the pods
+//	}
+//
+// # Compatibility
+//
+// The package follows semantic versioning with the module's release tags.
+// Within a major version exported identifiers are never removed or changed
+// incompatibly. Minor releases may add methods, options, request fields and
+// response fields, so don't rely on the exact set of fields of a type, and
+// construct requests with field names. Error messages are not part of the
+// API; compare errors with errors.Is and errors.As.
+package deployaja
@@ -0,0 +1,60 @@
+package deployaja
+
+import "github.com/deployaja/deployaja-cli/internal/api"
+
+// Error is returned for API responses with an error status. Use errors.As to
+// read the status code, error code and request ID.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    interface{}
+	RequestID  string
+
+	msg   string
+	cause *api.Error
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Is reports whether the error belongs to the class of one of the Err*
+// sentinels
+func (e *Error) Is(target error) bool {
+	return e.cause.Is(target)
+}
+
+// Sentinels matched by errors.Is against returned errors
+var (
+	ErrBadRequest   = api.ErrBadRequest
+	ErrUnauthorized = api.ErrUnauthorized
+	ErrForbidden    = api.ErrForbidden
+	ErrNotFound     = api.ErrNotFound
+	ErrConflict     = api.ErrConflict
+	ErrRateLimited  = api.ErrRateLimited
+	ErrServer       = api.ErrServer
+)
+
+// Rollout outcomes returned by WaitForDeployment and RolloutOutcome
+var (
+	// ErrRolloutFailed means the deployment reached a failed state
+	ErrRolloutFailed = api.ErrRolloutFailed
+	// ErrRolloutTimeout means the deployment didn't reach a final state in
+	// time, either within WaitOptions.Timeout or on the platform
+	ErrRolloutTimeout = api.ErrRolloutTimeout
+	// ErrRolloutUnknown means the deployment stopped in a state that is
+	// neither success nor failure
+	ErrRolloutUnknown = api.ErrRolloutUnknown
+)
+
+// ErrStopWatch can be returned by a WatchDeployment handler to end the watch
+// without error
+var ErrStopWatch = api.ErrStopWatch
+
+// RolloutOutcome classifies the final state of a rollout: nil for success,
+// otherwise an error matching ErrRolloutFailed, ErrRolloutTimeout or
+// ErrRolloutUnknown
+func RolloutOutcome(deployment *DeploymentStatus) error {
+	return api.RolloutOutcome(&api.DeploymentStatus{Name: deployment.Name, Status: deployment.Status})
+}
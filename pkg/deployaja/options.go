@@ -0,0 +1,184 @@
+package deployaja
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/deployaja/deployaja-cli/internal/api"
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// Version of the SDK, sent in the default User-Agent
+const Version = "1.0.0"
+
+// DefaultBaseURL is the production platform
+const DefaultBaseURL = "https://deployaja.id"
+
+// RetryPolicy controls retries of transient failures
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retries
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by the CLI
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy(api.DefaultRetryPolicy())
+}
+
+// WaitOptions control how WaitForDeployment waits for a rollout. Zero
+// values use the defaults of the CLI.
+type WaitOptions struct {
+	// Timeout bounds the whole wait, 10 minutes by default
+	Timeout time.Duration
+	// PollInterval is the fixed pause between polls of servers that can't
+	// stream status changes. Zero backs off between 2 and 30 seconds.
+	PollInterval time.Duration
+	// NotFoundGracePeriod tolerates a new deployment not being listed yet,
+	// 30 seconds by default
+	NotFoundGracePeriod time.Duration
+	// OnStatus, if not nil, is called with every change of the deployment
+	OnStatus func(*DeploymentStatus)
+}
+
+// Option configures a Client
+type Option func(*Client) error
+
+// WithBaseURL sets the platform URL, DefaultBaseURL by default
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) error {
+		if baseURL == "" {
+			return fmt.Errorf("base URL is empty")
+		}
+		c.baseURL = baseURL
+		return nil
+	}
+}
+
+// WithToken authenticates with a session token or API key. Refreshed session
+// tokens are kept in memory for later calls.
+func WithToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource authenticates with tokens from ts, consulted on every call
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) error {
+		if ts == nil {
+			return fmt.Errorf("token source is nil")
+		}
+		c.tokens = ts
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client. Its Timeout bounds regular calls;
+// log streams use its Transport without the timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return fmt.Errorf("HTTP client is nil")
+		}
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header, e.g. "release-bot/2.1"
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy. MaxRetries 0 disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		c.retry = policy
+		return nil
+	}
+}
+
+// TokenSource provides the token for each call
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenUpdater is implemented by token sources that keep refreshed tokens.
+// update receives the current token and returns the one to keep.
+type TokenUpdater interface {
+	UpdateToken(ctx context.Context, update func(current string) (string, error)) error
+}
+
+// TokenFunc adapts a function to a TokenSource
+type TokenFunc func(ctx context.Context) (string, error)
+
+func (f TokenFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken returns a source for a fixed token that keeps refreshed
+// session tokens in memory
+func StaticToken(token string) TokenSource {
+	return &staticToken{token: token}
+}
+
+type staticToken struct {
+	mu    sync.Mutex
+	token string
+}
+
+func (s *staticToken) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token, nil
+}
+
+func (s *staticToken) UpdateToken(ctx context.Context, update func(current string) (string, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := update(s.token)
+	if err != nil {
+		return err
+	}
+	s.token = token
+	return nil
+}
+
+// EnvToken returns a source reading DEPLOYAJA_TOKEN on every call
+func EnvToken() TokenSource {
+	return TokenFunc(func(ctx context.Context) (string, error) {
+		token := os.Getenv("DEPLOYAJA_TOKEN")
+		if token == "" {
+			return "", fmt.Errorf("DEPLOYAJA_TOKEN is not set")
+		}
+		return token, nil
+	})
+}
+
+// CLIToken returns a source sharing the token of 'aja login' for the default
+// context, including refreshes made by the CLI and by this client
+func CLIToken() TokenSource {
+	return cliToken{}
+}
+
+type cliToken struct{}
+
+func (cliToken) Token(ctx context.Context) (string, error) {
+	return config.LoadToken(), nil
+}
+
+func (cliToken) UpdateToken(ctx context.Context, update func(current string) (string, error)) error {
+	return config.UpdateToken(ctx, update)
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: api.DefaultTransportConfig().RequestTimeout}
+}
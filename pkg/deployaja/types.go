@@ -0,0 +1,367 @@
+package deployaja
+
+import (
+	"github.com/deployaja/deployaja-cli/internal/config"
+)
+
+// DeploymentConfig is the contents of deployaja.yaml
+type DeploymentConfig struct {
+	Name         string            `yaml:"name" json:"name"`
+	Description  string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Container    Container         `yaml:"container" json:"container"`
+	Resources    Resources         `yaml:"resources" json:"resources"`
+	Dependencies []Dependency      `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
+	Env          []EnvVar          `yaml:"env,omitempty" json:"env,omitempty"`
+	HealthCheck  HealthCheck       `yaml:"healthCheck,omitempty" json:"healthCheck,omitempty"`
+	Domain       string            `yaml:"domain,omitempty" json:"domain,omitempty"`
+	Volumes      []Volume          `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	EnvMap       map[string]string `yaml:"envMap,omitempty" json:"envMap,omitempty"`
+	DockerConfig *DockerConfig     `yaml:"dockerConfig,omitempty" json:"dockerConfig,omitempty"`
+}
+
+// Container is the image run by a deployment and the port it listens on
+type Container struct {
+	Image string `yaml:"image" json:"image"`
+	Port  int    `yaml:"port" json:"port"`
+}
+
+// Resources are the CPU and memory of each replica and the replica count
+type Resources struct {
+	CPU      string `yaml:"cpu" json:"cpu"`
+	Memory   string `yaml:"memory" json:"memory"`
+	Replicas int    `yaml:"replicas" json:"replicas"`
+}
+
+// HealthCheck is the HTTP probe deciding whether a replica is ready
+type HealthCheck struct {
+	Path                string `yaml:"path" json:"path"`
+	Port                int    `yaml:"port" json:"port"`
+	InitialDelaySeconds int    `yaml:"initialDelaySeconds" json:"initialDelaySeconds"`
+	PeriodSeconds       int    `yaml:"periodSeconds" json:"periodSeconds"`
+}
+
+// Dependency is a managed service, e.g. a database, provisioned with the deployment
+type Dependency struct {
+	Name    string                 `yaml:"name" json:"name"`
+	Type    string                 `yaml:"type" json:"type"`
+	Version string                 `yaml:"version" json:"version"`
+	Config  map[string]interface{} `yaml:"config,omitempty" json:"config,omitempty"`
+	Storage string                 `yaml:"storage,omitempty" json:"storage,omitempty"`
+}
+
+// EnvVar is an environment variable of the container
+type EnvVar struct {
+	Name        string `yaml:"name" json:"name"`
+	Value       string `yaml:"value" json:"value"`
+	UserManaged bool   `yaml:"userManaged" json:"userManaged"`
+}
+
+// Volume is persistent storage mounted into the container
+type Volume struct {
+	Name      string `yaml:"name" json:"name"`
+	Size      string `yaml:"size" json:"size"`
+	MountPath string `yaml:"mountPath" json:"mountPath"`
+}
+
+// DockerConfig holds registry credentials in the format of ~/.docker/config.json
+type DockerConfig struct {
+	Auths map[string]DockerAuth `yaml:"auths" json:"auths"`
+}
+
+// DockerAuth are the credentials of one registry
+type DockerAuth struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password" json:"password"`
+	Email    string `yaml:"email" json:"email"`
+	Auth     string `yaml:"auth" json:"auth"`
+}
+
+// LoadOptions select the overlay, values files and overrides applied by
+// LoadConfigWithOptions
+type LoadOptions struct {
+	// Env selects the overlay, e.g. staging for deployaja.staging.yaml
+	Env string
+	// Values are files merged over the overlay like overlays are
+	Values []string
+	// Set overrides fields by path, e.g. env[LOG_LEVEL].value=debug, with
+	// values read like unquoted YAML. Entries of Set, SetString and SetFile
+	// may hold several assignments separated by commas, \, is a comma kept
+	// in a value.
+	Set []string
+	// SetString overrides fields with string values
+	SetString []string
+	// SetFile overrides fields with the contents of files, path=file
+	SetFile []string
+	// Strict makes unset ${VAR} references without a default errors
+	// instead of empty values
+	Strict bool
+}
+
+// DeployResponse is the result of Deploy
+type DeployResponse struct {
+	DeploymentID  string `json:"deploymentId"`
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	EstimatedTime string `json:"estimatedTime,omitempty"`
+	URL           string `json:"url,omitempty"`
+}
+
+// ValidateResponse is the result of Validate
+type ValidateResponse struct {
+	Valid    bool     `json:"valid"`
+	Message  string   `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CostResponse is the result of EstimateCost
+type CostResponse struct {
+	EstimatedCost Cost          `json:"estimatedCost"`
+	Breakdown     CostBreakdown `json:"breakdown"`
+}
+
+// Cost is an estimated cost in Currency
+type Cost struct {
+	Monthly  float64 `json:"monthly"`
+	Daily    float64 `json:"daily"`
+	Currency string  `json:"currency"`
+}
+
+// CostBreakdown splits the monthly cost, dependencies by name
+type CostBreakdown struct {
+	Compute      float64            `json:"compute"`
+	Storage      float64            `json:"storage"`
+	Network      float64            `json:"network"`
+	Dependencies map[string]float64 `json:"dependencies,omitempty"`
+}
+
+// StatusResponse is the result of ListDeployments
+type StatusResponse struct {
+	Deployments []DeploymentStatus `json:"deployments"`
+}
+
+// DeploymentStatus is the state of a deployment and its pods
+type DeploymentStatus struct {
+	Name              string `json:"name"`
+	Status            string `json:"status"`
+	URL               string `json:"url,omitempty"`
+	LastDeployed      string `json:"lastDeployed"`
+	CreatedAt         string `json:"createdAt,omitempty"`
+	DesiredReplicas   int    `json:"desiredReplicas"`
+	AvailableReplicas int    `json:"availableReplicas"`
+	ReadyReplicas     int    `json:"readyReplicas"`
+	UpdatedReplicas   int    `json:"updatedReplicas"`
+	Pods              []Pod  `json:"pods"`
+}
+
+// Pod is a replica of a deployment
+type Pod struct {
+	Name              string            `json:"name"`
+	Phase             string            `json:"phase"`
+	Ready             bool              `json:"ready"`
+	RestartCount      int               `json:"restartCount"`
+	Age               string            `json:"age"`
+	Status            string            `json:"status"`
+	Reason            string            `json:"reason,omitempty"`
+	Message           string            `json:"message,omitempty"`
+	ContainerStatuses []ContainerStatus `json:"containerStatuses"`
+}
+
+// ContainerStatus is the state of a container of a pod
+type ContainerStatus struct {
+	Name         string `json:"name"`
+	Ready        bool   `json:"ready"`
+	RestartCount int    `json:"restartCount"`
+	State        string `json:"state"`
+	Reason       string `json:"reason,omitempty"`
+	Message      string `json:"message,omitempty"`
+}
+
+// DescribeResponse holds the pod details and events of a deployment as
+// reported by the platform
+type DescribeResponse struct {
+	Pod    map[string]interface{}   `json:"pod"`
+	Events []map[string]interface{} `json:"events"`
+}
+
+// RestartResponse is the result of Restart
+type RestartResponse struct {
+	Success bool        `json:"success"`
+	Data    RestartData `json:"data"`
+}
+
+// RestartData describes the restart started by the platform
+type RestartData struct {
+	Status        string        `json:"status"`
+	Message       string        `json:"message"`
+	Method        string        `json:"method"`
+	RolloutStatus RolloutStatus `json:"rolloutStatus"`
+}
+
+// RolloutStatus is the progress of a rollout. It is done when
+// ObservedGeneration reaches Generation and all replicas are updated.
+type RolloutStatus struct {
+	Generation         int `json:"generation"`
+	ObservedGeneration int `json:"observedGeneration"`
+	Replicas           int `json:"replicas"`
+	ReadyReplicas      int `json:"readyReplicas"`
+	UpdatedReplicas    int `json:"updatedReplicas"`
+}
+
+// LogEntry is a log line of a deployment
+type LogEntry struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+	Source    string `json:"source"`
+}
+
+// DependenciesResponse is the result of Dependencies
+type DependenciesResponse struct {
+	Dependencies []DependencyInfo `json:"dependencies"`
+}
+
+// DependencyInfo describes a managed dependency type
+type DependencyInfo struct {
+	Type           string            `json:"type"`
+	Name           string            `json:"name"`
+	Versions       []string          `json:"versions"`
+	DefaultVersion string            `json:"defaultVersion"`
+	Pricing        DependencyPricing `json:"pricing"`
+	Specs          DependencySpecs   `json:"specs"`
+}
+
+// DependencyPricing is the monthly price of a dependency
+type DependencyPricing struct {
+	Base    float64 `json:"base"`
+	Storage float64 `json:"storage,omitempty"`
+}
+
+// DependencySpecs are the resources of a dependency
+type DependencySpecs struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// SearchResponse is the result of SearchApps and ListApps
+type SearchResponse struct {
+	Apps  []MarketplaceApp `json:"apps"`
+	Total int              `json:"total"`
+}
+
+// MarketplaceApp is an app installable with InstallApp
+type MarketplaceApp struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Author      string   `json:"author"`
+	Version     string   `json:"version"`
+	Downloads   int      `json:"downloads"`
+	Rating      float64  `json:"rating"`
+	Image       string   `json:"image,omitempty"`
+	Repository  string   `json:"repository,omitempty"`
+}
+
+// InstallResponse is the result of InstallApp
+type InstallResponse struct {
+	DeploymentID   string `json:"deploymentId"`
+	AppName        string `json:"appName"`
+	DeploymentName string `json:"deploymentName"`
+	// Config is the base64 encoded deployaja.yaml of the installed app
+	Config        string `json:"config"`
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	EstimatedTime string `json:"estimatedTime,omitempty"`
+	URL           string `json:"url,omitempty"`
+}
+
+// APIKey is a service-account API key, without the key itself
+type APIKey struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix,omitempty"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"createdAt"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+}
+
+// CreateAPIKeyResponse is the result of CreateAPIKey
+type CreateAPIKeyResponse struct {
+	APIKey
+	// Key is only returned once, at creation
+	Key string `json:"key"`
+}
+
+// APIKeysResponse is the result of ListAPIKeys
+type APIKeysResponse struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// DeployRequest deploys or updates an application
+type DeployRequest struct {
+	Config *DeploymentConfig
+	// DryRun validates and plans the deployment without applying it
+	DryRun bool
+	// Registry holds credentials for a private image registry
+	Registry RegistryAuth
+}
+
+// RegistryAuth are the credentials used to pull a private image
+type RegistryAuth struct {
+	Server   string
+	Username string
+	Password string
+}
+
+// ListAppsRequest filters and pages the marketplace. Zero values use the
+// platform defaults.
+type ListAppsRequest struct {
+	Query    string
+	Category string
+	Author   string
+	Page     int
+	Limit    int
+	// SortBy is one of name, downloads, rating, createdAt or updatedAt
+	SortBy string
+	// SortOrder is asc or desc
+	SortOrder string
+}
+
+// InstallRequest installs an app from the marketplace
+type InstallRequest struct {
+	App string
+	// Name overrides the deployment name
+	Name string
+	// Domain is a custom domain for the ingress URL
+	Domain string
+	DryRun bool
+}
+
+// CreateAPIKeyRequest creates a service-account API key
+type CreateAPIKeyRequest struct {
+	Name   string
+	Scopes []string
+	// ExpiresIn is the lifetime in seconds, 0 means no expiry
+	ExpiresIn int
+}
+
+// LoadConfig reads a deployaja.yaml file
+func LoadConfig(path string) (*DeploymentConfig, error) {
+	cfg, err := config.LoadDeploymentConfigFromFile(path)
+	return fromConfig(cfg), err
+}
+
+// LoadConfigForEnv reads a deployaja.yaml file with the overlay of an
+// environment, e.g. deployaja.staging.yaml, merged over it
+func LoadConfigForEnv(path, env string) (*DeploymentConfig, error) {
+	cfg, err := config.LoadDeploymentConfigForEnv(path, env)
+	return fromConfig(cfg), err
+}
+
+// LoadConfigWithOptions reads a deployaja.yaml file with an overlay,
+// values files and --set style overrides applied
+func LoadConfigWithOptions(path string, opts LoadOptions) (*DeploymentConfig, error) {
+	cfg, err := config.LoadDeploymentConfigWithOptions(path, config.LoadOptions(opts))
+	return fromConfig(cfg), err
+}
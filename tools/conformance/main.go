@@ -0,0 +1,48 @@
+// Command conformance checks the API client against openapi.yaml.
+//
+//	go run ./tools/conformance [-spec openapi.yaml]
+//
+// It exits with status 1 when a client method drifted from the spec.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/deployaja/deployaja-cli/internal/conformance"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.yaml", "OpenAPI document to check the client against")
+	verbose := flag.Bool("v", false, "Also list the spec operations the client doesn't call")
+	flag.Parse()
+
+	spec, err := conformance.Load(*specPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "conformance: %v\n", err)
+		os.Exit(2)
+	}
+
+	report, err := conformance.Check(context.Background(), spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "conformance: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *verbose {
+		for _, op := range report.Unused {
+			fmt.Printf("unused: %s\n", op)
+		}
+	}
+
+	for _, problem := range report.Problems {
+		fmt.Println(problem)
+	}
+	if len(report.Problems) > 0 {
+		fmt.Fprintf(os.Stderr, "conformance: %d problem(s) between the client and %s\n", len(report.Problems), *specPath)
+		os.Exit(1)
+	}
+	fmt.Printf("conformance: %d client methods match %s\n", len(conformance.Calls), *specPath)
+}